@@ -1,9 +1,13 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
@@ -22,7 +26,40 @@ var (
 	SILVER    = color.RGBA{188, 198, 204, 255}
 )
 
+// outputFormats are the formats the -format flag accepts.
+var outputFormats = []string{"png", "svg", "pdf", "eps", "cmyk-jpg"}
+
 func main() {
+	shapeName := flag.String(
+		"shape", qrconst.SmileyFace.String(),
+		"module shape, one of: "+strings.Join(render.DefaultShapeRegistry.Names(), ", "),
+	)
+	formatList := flag.String(
+		"format", "png,svg",
+		"comma-separated output formats, of: "+strings.Join(outputFormats, ", "),
+	)
+	metadata := flag.Bool(
+		"metadata", false,
+		"record the payload and the settings in the PNG file",
+	)
+	strictContrast := flag.Bool(
+		"strict-contrast", false,
+		"fail, instead of warning, when the colors contrast too little to scan",
+	)
+	flag.Parse()
+
+	moduleShape, ok := render.DefaultShapeRegistry.Lookup(*shapeName)
+	if !ok {
+		fmt.Printf("unknown module shape %q\n", *shapeName)
+		os.Exit(2)
+	}
+	formats := strings.Split(*formatList, ",")
+	for _, format := range formats {
+		if !slices.Contains(outputFormats, format) {
+			fmt.Printf("unknown output format %q\n", format)
+			os.Exit(2)
+		}
+	}
 
 	// text := "8675309" //Numeric
 	// text := "HELLO WORLD" //Alphanumeric
@@ -43,34 +80,62 @@ func main() {
 
 	start := time.Now()
 	qrRenderer := render.NewRenderer().
-		WithModuleShape(qrconst.SmileyFace).
+		WithModuleShape(moduleShape).
 		WithBackgroundColor(bg).
-		WithForegroundColor(fg)
+		WithForegroundColor(fg).
+		WithPNGMetadata(*metadata)
 
-	// PNG Rendering
-	f, err := os.Create("main.png")
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	err = qrRenderer.RenderToWriter(*qrCode, f, qrconst.RenderPNG)
-	if err != nil {
-		fmt.Println(err)
-		return
+	if err := qrRenderer.CheckContrast(); err != nil {
+		if *strictContrast {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("warning:", err)
 	}
 
-	// SVG Rendering
-	f, err = os.Create("main.svg")
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	err = qrRenderer.RenderSVG(*qrCode, f)
-	if err != nil {
-		fmt.Println(err)
-		return
+	for _, format := range formats {
+		var err error
+		switch format {
+		case "png":
+			err = writeFile("main.png", func(w io.Writer) error {
+				return qrRenderer.RenderToWriter(*qrCode, w, qrconst.RenderPNG)
+			})
+		case "svg":
+			err = writeFile("main.svg", func(w io.Writer) error {
+				return qrRenderer.RenderSVG(*qrCode, w)
+			})
+		case "pdf":
+			err = writeFile("main.pdf", func(w io.Writer) error {
+				return qrRenderer.RenderToWriter(*qrCode, w, qrconst.RenderPDF)
+			})
+		case "eps":
+			err = writeFile("main.eps", func(w io.Writer) error {
+				return qrRenderer.RenderToWriter(*qrCode, w, qrconst.RenderEPS)
+			})
+		case "cmyk-jpg":
+			err = writeFile("main-cmyk.jpg", func(w io.Writer) error {
+				return qrRenderer.RenderToWriter(*qrCode, w, qrconst.RenderCMYKJPEG)
+			})
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 	}
 
 	elapsed := time.Since(start)
 	fmt.Printf("Rendering took %s\n", elapsed)
 }
+
+// writeFile creates the file, and closes it once written.
+func writeFile(name string, write func(w io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
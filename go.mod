@@ -2,7 +2,10 @@ module github.com/ahmadnaufalhakim/qrgen
 
 go 1.24.5
 
-require golang.org/x/text v0.31.0
+require (
+	golang.org/x/image v0.24.0
+	golang.org/x/text v0.31.0
+)
 
 require (
 	fyne.io/fyne/v2 v2.7.1
@@ -33,7 +36,6 @@ require (
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
 	github.com/yuin/goldmark v1.7.8 // indirect
-	golang.org/x/image v0.24.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
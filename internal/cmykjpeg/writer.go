@@ -0,0 +1,337 @@
+package cmykjpeg
+
+import (
+	"bufio"
+	"errors"
+	"image"
+	"io"
+	"math"
+)
+
+// DefaultQuality is the default quality encoding parameter.
+const DefaultQuality = 75
+
+// Options are the encoding parameters, like the ones of image/jpeg.
+// Quality ranges from 1 to 100 inclusive, higher is better. A positive
+// DPI is recorded as the resolution of the image, in the Photoshop
+// resolution resource, which Adobe applications read from CMYK files.
+type Options struct {
+	Quality int
+	DPI     float64
+}
+
+// zigzag maps the zig-zag order of the coefficients to their natural order.
+var zigzag = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// quantization is the luminance quantization table of the JPEG
+// specification, section K.1, in natural order. It is used for all
+// four components, as the inks carry detail alike.
+var quantization = [64]int{
+	16, 11, 10, 16, 24, 40, 51, 61,
+	12, 12, 14, 19, 26, 58, 60, 55,
+	14, 13, 16, 24, 40, 57, 69, 56,
+	14, 17, 22, 29, 51, 87, 80, 62,
+	18, 22, 37, 56, 68, 109, 103, 77,
+	24, 35, 55, 64, 81, 104, 113, 92,
+	49, 64, 78, 87, 103, 121, 120, 101,
+	72, 92, 95, 98, 112, 100, 103, 99,
+}
+
+// huffmanSpec is a Huffman table as the number of codes of each
+// length from 1 to 16, and the values in code order.
+type huffmanSpec struct {
+	count  [16]byte
+	values []byte
+}
+
+// The luminance DC and AC tables of the JPEG specification, section K.3.
+var (
+	dcSpec = huffmanSpec{
+		[16]byte{0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	}
+	acSpec = huffmanSpec{
+		[16]byte{0, 2, 1, 3, 3, 2, 4, 3, 5, 5, 4, 4, 0, 0, 1, 125},
+		[]byte{
+			0x01, 0x02, 0x03, 0x00, 0x04, 0x11, 0x05, 0x12,
+			0x21, 0x31, 0x41, 0x06, 0x13, 0x51, 0x61, 0x07,
+			0x22, 0x71, 0x14, 0x32, 0x81, 0x91, 0xa1, 0x08,
+			0x23, 0x42, 0xb1, 0xc1, 0x15, 0x52, 0xd1, 0xf0,
+			0x24, 0x33, 0x62, 0x72, 0x82, 0x09, 0x0a, 0x16,
+			0x17, 0x18, 0x19, 0x1a, 0x25, 0x26, 0x27, 0x28,
+			0x29, 0x2a, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+			0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+			0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+			0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+			0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79,
+			0x7a, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89,
+			0x8a, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98,
+			0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+			0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6,
+			0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3, 0xc4, 0xc5,
+			0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2, 0xd3, 0xd4,
+			0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda, 0xe1, 0xe2,
+			0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9, 0xea,
+			0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	}
+)
+
+// huffmanCode is the code of a value, in its length low bits.
+type huffmanCode struct {
+	bits   uint32
+	length uint
+}
+
+// codes returns the codes of the table, indexed by value.
+func (spec huffmanSpec) codes() [256]huffmanCode {
+	var codes [256]huffmanCode
+	code, k := uint32(0), 0
+	for length, count := range spec.count {
+		for range count {
+			codes[spec.values[k]] = huffmanCode{code, uint(length + 1)}
+			code++
+			k++
+		}
+		code <<= 1
+	}
+
+	return codes
+}
+
+// cosines holds cos((2x+1)uπ/16) at [u][x], for the DCT.
+var cosines = func() (c [8][8]float64) {
+	for u := range 8 {
+		for x := range 8 {
+			c[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 16)
+		}
+	}
+	return c
+}()
+
+// Encode writes the CMYK image m to w as a baseline JPEG, with the
+// Adobe APP14 marker flagging its four components as CMYK. Like the
+// files of Adobe applications, the components are stored inverted,
+// which image/jpeg decodes back. A nil o uses DefaultQuality.
+func Encode(w io.Writer, m *image.CMYK, o *Options) error {
+	b := m.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 || b.Dx() >= 1<<16 || b.Dy() >= 1<<16 {
+		return errors.New("cmykjpeg: image is too large or empty")
+	}
+
+	quality, dpi := DefaultQuality, 0.
+	if o != nil {
+		quality, dpi = min(100, max(1, o.Quality)), o.DPI
+	}
+	// Scale the table like libjpeg does
+	scale := 200 - 2*quality
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	var quant [64]int
+	for i, q := range quantization {
+		quant[i] = min(255, max(1, (q*scale+50)/100))
+	}
+
+	e := &encoder{w: bufio.NewWriter(w), dc: dcSpec.codes(), ac: acSpec.codes()}
+	e.write([]byte{0xff, 0xd8})
+	// APP14 "Adobe", version 100, no flags, transform 0 (CMYK)
+	e.marker(0xee, []byte{'A', 'd', 'o', 'b', 'e', 0, 100, 0, 0, 0, 0, 0})
+	if dpi > 0 {
+		e.marker(0xed, resolutionInfo(dpi))
+	}
+
+	dqt := []byte{0}
+	for _, i := range zigzag {
+		dqt = append(dqt, byte(quant[i]))
+	}
+	e.marker(0xdb, dqt)
+
+	// Four components without subsampling, all using table 0
+	sof := []byte{8, byte(b.Dy() >> 8), byte(b.Dy()), byte(b.Dx() >> 8), byte(b.Dx()), 4}
+	for id := range byte(4) {
+		sof = append(sof, id+1, 0x11, 0)
+	}
+	e.marker(0xc0, sof)
+
+	for class, spec := range []huffmanSpec{dcSpec, acSpec} {
+		dht := append([]byte{byte(class << 4)}, spec.count[:]...)
+		e.marker(0xc4, append(dht, spec.values...))
+	}
+
+	sos := []byte{4}
+	for id := range byte(4) {
+		sos = append(sos, id+1, 0)
+	}
+	e.marker(0xda, append(sos, 0, 63, 0))
+
+	var prevDC [4]int
+	var block [64]float64
+	for y := b.Min.Y; y < b.Max.Y; y += 8 {
+		for x := b.Min.X; x < b.Max.X; x += 8 {
+			for ch := range 4 {
+				// Blocks past the edges repeat the last pixels
+				for j := range 8 {
+					for i := range 8 {
+						px, py := min(x+i, b.Max.X-1), min(y+j, b.Max.Y-1)
+						ink := m.Pix[m.PixOffset(px, py)+ch]
+						block[8*j+i] = float64(255-ink) - 128
+					}
+				}
+				prevDC[ch] = e.block(&block, &quant, prevDC[ch])
+			}
+		}
+	}
+
+	// Pad the last byte with ones
+	e.bits(0x7f, 7)
+	e.write([]byte{0xff, 0xd9})
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// resolutionInfo returns the APP13 segment data holding the Photoshop
+// ResolutionInfo resource, with both resolutions in pixels per inch as
+// 16.16 fixed point numbers, and the sizes shown in inches.
+func resolutionInfo(dpi float64) []byte {
+	fixed := uint32(min(math.MaxUint32, math.Round(dpi*65536)))
+	res := []byte{byte(fixed >> 24), byte(fixed >> 16), byte(fixed >> 8), byte(fixed), 0, 1, 0, 1}
+
+	data := []byte("Photoshop 3.0\x008BIM")
+	// The resource id, an empty padded name, and the size
+	data = append(data, 0x03, 0xed, 0, 0, 0, 0, 0, 16)
+	return append(append(data, res...), res...)
+}
+
+type encoder struct {
+	w      *bufio.Writer
+	err    error
+	dc, ac [256]huffmanCode
+	// acc holds the pending bits, n of them
+	acc uint32
+	n   uint
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+// marker writes a marker segment with its length.
+func (e *encoder) marker(code byte, data []byte) {
+	n := len(data) + 2
+	e.write([]byte{0xff, code, byte(n >> 8), byte(n)})
+	e.write(data)
+}
+
+// bits writes the length low bits of v to the entropy coded data,
+// stuffing a zero byte after every 0xff byte.
+func (e *encoder) bits(v uint32, length uint) {
+	e.acc = e.acc<<length | v&(1<<length-1)
+	e.n += length
+	for e.n >= 8 {
+		e.n -= 8
+		c := byte(e.acc >> e.n)
+		e.write([]byte{c})
+		if c == 0xff {
+			e.write([]byte{0})
+		}
+	}
+	e.acc &= 1<<e.n - 1
+}
+
+func (e *encoder) code(c huffmanCode) {
+	e.bits(c.bits, c.length)
+}
+
+// magnitude returns the size category of v, and its bits as written
+// after the category, with negative values one less in two's complement.
+func magnitude(v int) (size uint, bits uint32) {
+	a := v
+	if a < 0 {
+		a = -a
+		v--
+	}
+	for a > 0 {
+		size++
+		a >>= 1
+	}
+	return size, uint32(v)
+}
+
+// block transforms, quantizes and writes a block of level shifted
+// samples, and returns its DC coefficient.
+func (e *encoder) block(samples *[64]float64, quant *[64]int, prevDC int) int {
+	var rows, coefs [64]float64
+	for y := range 8 {
+		for u := range 8 {
+			var s float64
+			for x := range 8 {
+				s += samples[8*y+x] * cosines[u][x]
+			}
+			rows[8*y+u] = s
+		}
+	}
+	for v := range 8 {
+		for u := range 8 {
+			var s float64
+			for y := range 8 {
+				s += rows[8*y+u] * cosines[v][y]
+			}
+			cu, cv := 1., 1.
+			if u == 0 {
+				cu = math.Sqrt2 / 2
+			}
+			if v == 0 {
+				cv = math.Sqrt2 / 2
+			}
+			coefs[8*v+u] = s * cu * cv / 4
+		}
+	}
+
+	var q [64]int
+	for i := range q {
+		q[i] = int(math.Round(coefs[i] / float64(quant[i])))
+	}
+
+	size, bits := magnitude(q[0] - prevDC)
+	e.code(e.dc[size])
+	e.bits(bits, size)
+
+	run := 0
+	for k := 1; k < 64; k++ {
+		v := q[zigzag[k]]
+		if v == 0 {
+			run++
+			continue
+		}
+		for run > 15 {
+			// ZRL, a run of 16 zeros
+			e.code(e.ac[0xf0])
+			run -= 16
+		}
+		size, bits := magnitude(v)
+		e.code(e.ac[byte(run<<4)|byte(size)])
+		e.bits(bits, size)
+		run = 0
+	}
+	if run > 0 {
+		// EOB
+		e.code(e.ac[0x00])
+	}
+
+	return q[0]
+}
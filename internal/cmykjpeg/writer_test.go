@@ -0,0 +1,84 @@
+package cmykjpeg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// testImage returns a CMYK image of ink ramps, with
+// a checkerboard of sharp edges across the blocks.
+func testImage(w, h int) *image.CMYK {
+	m := image.NewCMYK(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := color.CMYK{uint8(x * 255 / max(1, w-1)), uint8(y * 255 / max(1, h-1)), 0, 0}
+			if (x/8+y/8)%2 == 0 {
+				c.Y, c.K = 200, 40
+			}
+			m.SetCMYK(x, y, c)
+		}
+	}
+	return m
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, size := range []image.Point{{1, 1}, {13, 7}, {64, 64}, {301, 157}} {
+		m := testImage(size.X, size.Y)
+		for _, tc := range []struct {
+			quality     int
+			mean, worst float64
+		}{
+			{50, 2, 8},
+			{75, 1, 4},
+			{100, .1, 1},
+		} {
+			var b bytes.Buffer
+			if err := Encode(&b, m, &Options{Quality: tc.quality}); err != nil {
+				t.Fatal(err)
+			}
+			img, err := jpeg.Decode(&b)
+			if err != nil {
+				t.Fatalf("%v quality %d: %v", size, tc.quality, err)
+			}
+			got, ok := img.(*image.CMYK)
+			if !ok || got.Bounds() != m.Bounds() {
+				t.Fatalf("%v quality %d: got %T of bounds %v, want *image.CMYK of bounds %v", size, tc.quality, img, img.Bounds(), m.Bounds())
+			}
+
+			var sum, worst int
+			for i := range m.Pix {
+				d := int(m.Pix[i]) - int(got.Pix[i])
+				d = max(d, -d)
+				sum += d
+				worst = max(worst, d)
+			}
+			if mean := float64(sum) / float64(len(m.Pix)); mean > tc.mean || float64(worst) > tc.worst {
+				t.Errorf("%v quality %d: got mean error %.2f, worst %d, want at most %g and %g", size, tc.quality, mean, worst, tc.mean, tc.worst)
+			}
+		}
+	}
+}
+
+func TestEncodeDefaultOptions(t *testing.T) {
+	m := testImage(17, 9)
+	var withNil, withDefault bytes.Buffer
+	if err := Encode(&withNil, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Encode(&withDefault, m, &Options{Quality: DefaultQuality}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(withNil.Bytes(), withDefault.Bytes()) {
+		t.Error("nil options differ from the default quality")
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	if err := Encode(&bytes.Buffer{}, image.NewCMYK(image.Rect(0, 0, 0, 5)), nil); err == nil {
+		t.Error("Encode succeeded with an empty image")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"image"
 	"image/color"
 	"log"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -36,6 +38,7 @@ type QRGeneratorApp struct {
 	currentTab string
 
 	// Input fields
+	tabs                 *container.AppTabs
 	plainTextEntry       *widget.Entry
 	urlEntry             *widget.Entry
 	twitterEntry         *widget.Entry
@@ -57,16 +60,26 @@ type QRGeneratorApp struct {
 	kernelTypeSelect   *widget.Select
 	radiusSlider       *widget.Slider
 	radiusLabel        *widget.Label
+	printSizeEntry     *widget.Entry
+	dpiEntry           *widget.Entry
+	frameShapeSelect   *widget.Select
+	captionEntry       *widget.Entry
+	captionAboveCheck  *widget.Check
+	metadataCheck      *widget.Check
 
 	// Preview
 	previewContainer *fyne.Container
 	previewImage     *canvas.Image
+	contrastLabel    *widget.Label
 
 	// Data
 	currentQRCode  *qrcode.QRCode
 	lastBuildState *qrBuildState
 	renderer       *render.QRRenderer
 	updateTimer    *time.Timer
+	// printColors holds the targets set to CMYK or spot
+	// colors, which JPEG files are saved as CMYK for
+	printColors map[string]bool
 
 	dirtyQR     bool // encoding/matrix dirty
 	dirtyRender bool // visual dirty
@@ -104,6 +117,7 @@ func NewQRGeneratorApp() *QRGeneratorApp {
 		dirtyRender: true,
 		qrIcon:      qrIconResource,
 		paintIcon:   paintIconResource,
+		printColors: make(map[string]bool),
 	}
 	qrGenApp.updateTimer.Stop()
 
@@ -117,7 +131,7 @@ func (a *QRGeneratorApp) Run() {
 
 func (a *QRGeneratorApp) buildUI() {
 	// Create tab container for different input types
-	tabs := container.NewAppTabs(
+	a.tabs = container.NewAppTabs(
 		container.NewTabItem("Plain Text", a.buildPlainTextTab()),
 		container.NewTabItem("URL", a.buildURLTab()),
 		container.NewTabItem("Twitter", a.buildTwitterTab()),
@@ -126,7 +140,7 @@ func (a *QRGeneratorApp) buildUI() {
 		container.NewTabItem("Telephone", a.buildTelephoneTab()),
 	)
 
-	tabs.OnSelected = func(ti *container.TabItem) {
+	a.tabs.OnSelected = func(ti *container.TabItem) {
 		a.currentTab = strings.ToLower(strings.ReplaceAll(ti.Text, " ", ""))
 		a.markQRDirty()
 	}
@@ -140,17 +154,18 @@ func (a *QRGeneratorApp) buildUI() {
 	// Preview panel
 	previewPanel := a.buildPreviewPanel()
 
-	// Save button
+	// Save and open buttons
 	saveButton := widget.NewButton("Save QR Code", a.showFileSaveDialog)
+	openButton := widget.NewButton("Open QR Code", a.showFileOpenDialog)
 
 	// Layout
 	leftPanel := container.NewVBox(
-		tabs,
+		a.tabs,
 		widget.NewSeparator(),
 		container.NewPadded(basicOptionsPanel),
 		widget.NewSeparator(),
 		container.NewPadded(advancedOptionsPanel),
-		saveButton,
+		container.NewGridWithColumns(2, saveButton, openButton),
 	)
 
 	mainContent := container.NewHSplit(
@@ -286,25 +301,12 @@ func (a *QRGeneratorApp) buildBasicOptionsPanel() fyne.CanvasObject {
 		a.markQRDirty()
 	}))
 
-	// Module shape
-	moduleShapes := []string{
-		"Square",
-		"Circle",
-		"HorizontalBlob",
-		"VerticalBlob",
-		"Blob",
-		"LeftLeaf",
-		"RightLeaf",
-		"Diamond",
-		"WaterDroplet",
-		"Star4",
-		"Star5",
-		"Star6",
-		"Xs",
-		"Octagon",
-		"SmileyFace",
-		"Pointillism",
+	// Module shape, followed by the shapes registered by the application
+	var moduleShapes []string
+	for _, s := range moduleShapeLabels {
+		moduleShapes = append(moduleShapes, s.label)
 	}
+	moduleShapes = append(moduleShapes, render.DefaultShapeRegistry.Names()[qrconst.Pointillism+1:]...)
 	a.moduleShapeSelect = widget.NewSelect(
 		moduleShapes,
 		func(s string) {
@@ -331,8 +333,16 @@ func (a *QRGeneratorApp) buildBasicOptionsPanel() fyne.CanvasObject {
 			minVersionSlider,
 		)),
 		widget.NewFormItem("Module Shape", a.moduleShapeSelect),
-		widget.NewFormItem("Background Color", a.backgroundColorBtn),
-		widget.NewFormItem("Foreground Color", a.foregroundColorBtn),
+		widget.NewFormItem("Background Color", container.NewBorder(
+			nil, nil, nil,
+			widget.NewButton("CMYK", func() { a.choosePrintColor("background") }),
+			a.backgroundColorBtn,
+		)),
+		widget.NewFormItem("Foreground Color", container.NewBorder(
+			nil, nil, nil,
+			widget.NewButton("CMYK", func() { a.choosePrintColor("foreground") }),
+			a.foregroundColorBtn,
+		)),
 	)
 
 	return widget.NewCard("Basic Options", "", container.NewPadded(form))
@@ -375,6 +385,7 @@ func (a *QRGeneratorApp) buildRenderAdvancedPanel() fyne.CanvasObject {
 
 	// Kernel type
 	kernelTypes := []string{
+		"None",
 		"Lanczos2",
 		"CubicSmooth",
 		"Gaussian",
@@ -410,7 +421,35 @@ func (a *QRGeneratorApp) buildRenderAdvancedPanel() fyne.CanvasObject {
 			a.markRenderDirty()
 		},
 	)
-	a.kernelTypeSelect.SetSelected("Lanczos2")
+	a.kernelTypeSelect.SetSelected("None")
+
+	// Physical size, for printing the saved images
+	a.printSizeEntry = widget.NewEntry()
+	a.printSizeEntry.SetPlaceHolder("Auto")
+	a.printSizeEntry.OnChanged = func(string) { a.markRenderDirty() }
+	a.dpiEntry = widget.NewEntry()
+	a.dpiEntry.SetPlaceHolder("96")
+	a.dpiEntry.OnChanged = func(string) { a.markRenderDirty() }
+
+	// Frame and caption
+	var frameShapes []string
+	for shape := qrconst.FrameNone; shape <= qrconst.FrameTicket; shape++ {
+		frameShapes = append(frameShapes, shape.String())
+	}
+	a.frameShapeSelect = widget.NewSelect(
+		frameShapes,
+		func(s string) {
+			a.markRenderDirty()
+		},
+	)
+	a.frameShapeSelect.SetSelected(qrconst.FrameNone.String())
+	a.captionEntry = widget.NewEntry()
+	a.captionEntry.SetPlaceHolder("SCAN ME")
+	a.captionEntry.OnChanged = func(string) { a.markRenderDirty() }
+	a.captionAboveCheck = widget.NewCheck("Above the symbol", func(bool) { a.markRenderDirty() })
+
+	// Settings recorded in saved PNG files, for Open QR Code
+	a.metadataCheck = widget.NewCheck("Record the settings in PNG files", func(bool) { a.markRenderDirty() })
 
 	form := widget.NewForm(
 		widget.NewFormItem("Kernel Type", a.kernelTypeSelect),
@@ -418,6 +457,14 @@ func (a *QRGeneratorApp) buildRenderAdvancedPanel() fyne.CanvasObject {
 			a.radiusLabel,
 			a.radiusSlider,
 		)),
+		widget.NewFormItem("Print Size (mm)", a.printSizeEntry),
+		widget.NewFormItem("Print DPI", a.dpiEntry),
+		widget.NewFormItem("Frame", a.frameShapeSelect),
+		widget.NewFormItem("Caption", container.NewVBox(
+			a.captionEntry,
+			a.captionAboveCheck,
+		)),
+		widget.NewFormItem("PNG Metadata", a.metadataCheck),
 	)
 
 	return container.NewPadded(form)
@@ -477,7 +524,16 @@ func (a *QRGeneratorApp) buildPreviewPanel() fyne.CanvasObject {
 
 	a.previewContainer = container.NewStack(a.previewImage)
 
-	return container.NewPadded(a.previewContainer)
+	// Warns about colors too close to scan reliably
+	a.contrastLabel = widget.NewLabel("")
+	a.contrastLabel.Importance = widget.WarningImportance
+	a.contrastLabel.Wrapping = fyne.TextWrapWord
+	a.contrastLabel.Hide()
+
+	return container.NewPadded(container.NewBorder(
+		nil, a.contrastLabel, nil, nil,
+		a.previewContainer,
+	))
 }
 
 func (a *QRGeneratorApp) markQRDirty() {
@@ -510,10 +566,18 @@ func (a *QRGeneratorApp) scheduleQRUpdate() {
 		if img == nil {
 			return
 		}
+		contrastErr := a.renderer.CheckContrast()
 
 		fyne.Do(func() {
 			a.previewImage.Image = img
 			a.previewImage.Refresh()
+
+			if contrastErr != nil {
+				a.contrastLabel.SetText(fmt.Sprintf("Warning: %v, the QR Code may not scan", contrastErr))
+				a.contrastLabel.Show()
+			} else {
+				a.contrastLabel.Hide()
+			}
 		})
 	}()
 }
@@ -552,7 +616,11 @@ func (a *QRGeneratorApp) buildPreviewImage() image.Image {
 		a.renderer.
 			WithModuleShape(a.getModuleShape()).
 			WithKernelType(a.getKernelType()).
-			WithRadius(a.getRadius())
+			WithRadius(a.getRadius()).
+			WithPhysicalSize(parsePositive(a.printSizeEntry)).
+			WithDPI(parsePositive(a.dpiEntry)).
+			WithFrame(a.getFrameOptions()).
+			WithPNGMetadata(a.metadataCheck.Checked)
 
 		a.dirtyRender = false
 	}
@@ -577,7 +645,7 @@ func (a *QRGeneratorApp) showFileSaveDialog() {
 	fileSaveDialog.SetFileName("qrcode.png")
 
 	fileSaveDialog.SetFilter(storage.NewExtensionFileFilter([]string{
-		".png", ".jpg", ".jpeg",
+		".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tif", ".tiff", ".pdf", ".eps",
 	}))
 
 	fileSaveDialog.Show()
@@ -598,6 +666,19 @@ func (a *QRGeneratorApp) saveQRCode(writer fyne.URIWriteCloser) {
 		format = qrconst.RenderPNG
 	case ".jpg", ".jpeg":
 		format = qrconst.RenderJPEG
+		if a.printColors["background"] || a.printColors["foreground"] {
+			format = qrconst.RenderCMYKJPEG
+		}
+	case ".gif":
+		format = qrconst.RenderGIF
+	case ".bmp":
+		format = qrconst.RenderBMP
+	case ".tif", ".tiff":
+		format = qrconst.RenderTIFF
+	case ".pdf":
+		format = qrconst.RenderPDF
+	case ".eps":
+		format = qrconst.RenderEPS
 	default:
 		writer.Close()
 
@@ -629,31 +710,89 @@ func (a *QRGeneratorApp) saveQRCode(writer fyne.URIWriteCloser) {
 	dialog.ShowInformation("Saved", fmt.Sprintf("QR Code saved to %s", path), a.window)
 }
 
+// showFileOpenDialog opens a PNG saved by QRGen,
+// and restores the settings it was saved with.
+func (a *QRGeneratorApp) showFileOpenDialog() {
+	fileOpenDialog := dialog.NewFileOpen(
+		func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			settings, err := render.ReadPNGSettings(reader)
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			a.restoreSettings(settings)
+		},
+		a.window,
+	)
+
+	fileOpenDialog.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+
+	fileOpenDialog.Show()
+}
+
+// restoreSettings sets the inputs to the settings of an opened QR Code.
+// The payload is restored as plain text, with its mask pattern forced.
+func (a *QRGeneratorApp) restoreSettings(s *render.PNGSettings) {
+	a.tabs.SelectIndex(0)
+	a.plainTextEntry.SetText(s.Payload)
+
+	for _, option := range a.ecLevelRadio.Options {
+		if strings.HasPrefix(option, s.ECLevel.String()+" ") {
+			a.ecLevelRadio.SetSelected(option)
+		}
+	}
+	a.minVersionEntry.SetText(strconv.Itoa(s.Version))
+	a.maskPatternSelect.SetSelected(fmt.Sprintf("Pattern %d", s.MaskNum))
+
+	// Shapes hidden from the list are added to it
+	shapeLabel := moduleShapeLabel(s.Shape)
+	if !slices.Contains(a.moduleShapeSelect.Options, shapeLabel) {
+		a.moduleShapeSelect.Options = append(a.moduleShapeSelect.Options, shapeLabel)
+	}
+	a.moduleShapeSelect.SetSelected(shapeLabel)
+	a.renderer.
+		WithBackgroundColor(s.Background).
+		WithForegroundColor(s.Foreground)
+	a.backgroundColorBtn.SetText(fmt.Sprintf("Background: RGBA(%d,%d,%d,%d)",
+		s.Background.R, s.Background.G, s.Background.B, s.Background.A))
+	a.foregroundColorBtn.SetText(fmt.Sprintf("Foreground: RGBA(%d,%d,%d,%d)",
+		s.Foreground.R, s.Foreground.G, s.Foreground.B, s.Foreground.A))
+	a.printColors["background"] = false
+	a.printColors["foreground"] = false
+
+	a.kernelTypeSelect.SetSelected(s.Kernel)
+	a.radiusSlider.SetValue(float64(s.Radius))
+
+	a.markQRDirty()
+}
+
 func (a *QRGeneratorApp) chooseColor(target string) {
 	colorPicker := dialog.NewColorPicker("Choose color", "Select a color", func(c color.Color) {
 		if c == nil {
 			return
 		}
 
-		r, g, b, _ := c.RGBA()
-		rgba := color.RGBA{
-			R: uint8(r >> 8),
-			G: uint8(g >> 8),
-			B: uint8(b >> 8),
-			A: 255,
-		}
+		// Keep the picked alpha, so that transparent
+		// backgrounds can be saved as PNG
+		nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
 
 		switch target {
 		case "background":
-			a.renderer.WithBackgroundColor(rgba)
-			a.backgroundColorBtn.SetText(fmt.Sprintf("Background: RGB(%d,%d,%d)",
-				rgba.R, rgba.G, rgba.B))
+			a.renderer.WithBackgroundColor(nrgba)
+			a.backgroundColorBtn.SetText(fmt.Sprintf("Background: RGBA(%d,%d,%d,%d)",
+				nrgba.R, nrgba.G, nrgba.B, nrgba.A))
 		case "foreground":
-			a.renderer.WithForegroundColor(rgba)
-			a.foregroundColorBtn.SetText(fmt.Sprintf("Foreground: RGB(%d,%d,%d)",
-				rgba.R, rgba.G, rgba.B))
+			a.renderer.WithForegroundColor(nrgba)
+			a.foregroundColorBtn.SetText(fmt.Sprintf("Foreground: RGBA(%d,%d,%d,%d)",
+				nrgba.R, nrgba.G, nrgba.B, nrgba.A))
 		}
 
+		a.printColors[target] = false
 		a.markRenderDirty()
 	}, a.window)
 	colorPicker.Advanced = true // enable color wheel
@@ -661,6 +800,69 @@ func (a *QRGeneratorApp) chooseColor(target string) {
 	colorPicker.Show()
 }
 
+// choosePrintColor asks for a CMYK color, in percents, optionally
+// named as a spot color printed at a tint.
+func (a *QRGeneratorApp) choosePrintColor(target string) {
+	cyanEntry, magentaEntry := widget.NewEntry(), widget.NewEntry()
+	yellowEntry, blackEntry := widget.NewEntry(), widget.NewEntry()
+	spotEntry, tintEntry := widget.NewEntry(), widget.NewEntry()
+	for _, entry := range []*widget.Entry{cyanEntry, magentaEntry, yellowEntry, blackEntry} {
+		entry.SetText("0")
+	}
+	spotEntry.SetPlaceHolder("None")
+	tintEntry.SetText("100")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Cyan %", cyanEntry),
+		widget.NewFormItem("Magenta %", magentaEntry),
+		widget.NewFormItem("Yellow %", yellowEntry),
+		widget.NewFormItem("Black %", blackEntry),
+		widget.NewFormItem("Spot color name", spotEntry),
+		widget.NewFormItem("Tint %", tintEntry),
+	}
+	dialog.ShowForm("Choose print color", "OK", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var percents [5]float64
+		entries := []*widget.Entry{cyanEntry, magentaEntry, yellowEntry, blackEntry, tintEntry}
+		for i, entry := range entries {
+			v, err := strconv.ParseFloat(strings.TrimSpace(entry.Text), 64)
+			if err != nil || v < 0 || v > 100 {
+				dialog.ShowError(fmt.Errorf("%s must be a percent from 0 to 100", items[i].Text), a.window)
+				return
+			}
+			percents[i] = v
+		}
+		ink := func(v float64) uint8 {
+			return uint8(math.Round(v * 255 / 100))
+		}
+
+		var c color.Color = color.CMYK{ink(percents[0]), ink(percents[1]), ink(percents[2]), ink(percents[3])}
+		text := fmt.Sprintf("CMYK(%g,%g,%g,%g)", percents[0], percents[1], percents[2], percents[3])
+		if name := strings.TrimSpace(spotEntry.Text); name != "" {
+			c = render.SpotColor{Name: name, Tint: percents[4] / 100, CMYK: c.(color.CMYK)}
+			text = fmt.Sprintf("%s %g%%", name, percents[4])
+		}
+		// The buttons show the RGB color previewed on screen too
+		preview := color.NRGBAModel.Convert(c).(color.NRGBA)
+		text += fmt.Sprintf(" ~ RGB(%d,%d,%d)", preview.R, preview.G, preview.B)
+
+		switch target {
+		case "background":
+			a.renderer.WithBackgroundColor(c)
+			a.backgroundColorBtn.SetText("Background: " + text)
+		case "foreground":
+			a.renderer.WithForegroundColor(c)
+			a.foregroundColorBtn.SetText("Foreground: " + text)
+		}
+
+		a.printColors[target] = true
+		a.markRenderDirty()
+	}, a.window)
+}
+
 func (a *QRGeneratorApp) getCurrentInput() string {
 	switch a.currentTab {
 	case "plaintext":
@@ -753,43 +955,56 @@ func (a *QRGeneratorApp) getMaskPattern() *int {
 	return &maskNum
 }
 
+// moduleShapeLabels are the built-in module shapes listed by the GUI.
+var moduleShapeLabels = []struct {
+	label string
+	shape qrconst.ModuleShape
+}{
+	{"Square", qrconst.Square},
+	{"Circle", qrconst.Circle},
+	{"HorizontalBlob", qrconst.HorizontalBlob},
+	{"VerticalBlob", qrconst.VerticalBlob},
+	{"Blob", qrconst.Blob},
+	{"LeftLeaf", qrconst.LeftLeaf},
+	{"RightLeaf", qrconst.RightLeaf},
+	{"Diamond", qrconst.Diamond},
+	{"WaterDroplet", qrconst.WaterDroplet},
+	{"Star4", qrconst.Star4},
+	{"Star5", qrconst.Star5},
+	{"Star6", qrconst.Star6},
+	{"Xs", qrconst.Xs},
+	{"Octagon", qrconst.Octagon},
+	{"SmileyFace", qrconst.SmileyFace},
+	{"Pointillism", qrconst.Pointillism},
+}
+
+// moduleShapeLabel returns the label of the shape registered under
+// the name, or the name of the shapes without a label.
+func moduleShapeLabel(name string) string {
+	if shape, ok := render.DefaultShapeRegistry.Lookup(name); ok {
+		for _, s := range moduleShapeLabels {
+			if s.shape == shape {
+				return s.label
+			}
+		}
+	}
+
+	return name
+}
+
 func (a *QRGeneratorApp) getModuleShape() qrconst.ModuleShape {
-	switch a.moduleShapeSelect.Selected {
-	case "Square":
-		return qrconst.Square
-	case "Circle":
-		return qrconst.Circle
-	case "HorizontalBlob":
-		return qrconst.HorizontalBlob
-	case "VerticalBlob":
-		return qrconst.VerticalBlob
-	case "Blob":
-		return qrconst.Blob
-	case "LeftLeaf":
-		return qrconst.LeftLeaf
-	case "RightLeaf":
-		return qrconst.RightLeaf
-	case "Diamond":
-		return qrconst.Diamond
-	case "WaterDroplet":
-		return qrconst.WaterDroplet
-	case "Star4":
-		return qrconst.Star4
-	case "Star5":
-		return qrconst.Star5
-	case "Star6":
-		return qrconst.Star6
-	case "Xs":
-		return qrconst.Xs
-	case "Octagon":
-		return qrconst.Octagon
-	case "SmileyFace":
-		return qrconst.SmileyFace
-	case "Pointillism":
-		return qrconst.Pointillism
-	default:
+	for _, s := range moduleShapeLabels {
+		if s.label == a.moduleShapeSelect.Selected {
+			return s.shape
+		}
+	}
+
+	shape, ok := render.DefaultShapeRegistry.Lookup(a.moduleShapeSelect.Selected)
+	if !ok {
 		return qrconst.Square
 	}
+
+	return shape
 }
 
 func (a *QRGeneratorApp) getKernelType() string {
@@ -804,6 +1019,38 @@ func (a *QRGeneratorApp) getRadius() int {
 	return int(a.radiusSlider.Value)
 }
 
+func (a *QRGeneratorApp) getFrameOptions() render.FrameOptions {
+	opts := render.DefaultFrameOptions()
+	opts.Shape = qrconst.FrameNone
+	for shape := qrconst.FrameNone; shape <= qrconst.FrameTicket; shape++ {
+		if shape.String() == a.frameShapeSelect.Selected {
+			opts.Shape = shape
+		}
+	}
+
+	opts.Caption = a.captionEntry.Text
+	if a.captionAboveCheck.Checked {
+		opts.Position = qrconst.CaptionAbove
+	}
+
+	return opts
+}
+
+// parsePositive returns the number in the entry,
+// or 0 when it is empty or not a positive number.
+func parsePositive(entry *widget.Entry) float64 {
+	if entry == nil {
+		return 0
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(entry.Text), 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+
+	return v
+}
+
 func digitsOnly(s string) string {
 	var b strings.Builder
 	for _, r := range s {
@@ -0,0 +1,152 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Ref is a reference to an indirect object.
+type Ref int
+
+func (ref Ref) String() string {
+	return strconv.Itoa(int(ref)) + " 0 R"
+}
+
+// Writer writes the objects of a PDF file as they come, and the cross
+// reference table on Close. Objects may be written in any order, once
+// their number is allocated. The first error is returned by Close.
+type Writer struct {
+	w       io.Writer
+	offset  int
+	offsets []int
+	err     error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	pw := &Writer{w: w}
+	// The binary comment marks the file as binary for transfer tools
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	return pw
+}
+
+// Alloc allocates the number of a new object.
+func (pw *Writer) Alloc() Ref {
+	pw.offsets = append(pw.offsets, -1)
+	return Ref(len(pw.offsets))
+}
+
+// Object writes the object ref, given in PDF syntax, e.g. "<< /Type /Catalog >>".
+func (pw *Writer) Object(ref Ref, value string) {
+	pw.begin(ref)
+	pw.printf("%s\nendobj\n", value)
+}
+
+// Stream writes the stream ref compressed, with the entries of
+// its dictionary given in PDF syntax, e.g. "/Subtype /Image".
+func (pw *Writer) Stream(ref Ref, dict string, data []byte) {
+	var buf bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	zw.Write(data)
+	zw.Close()
+
+	if dict != "" {
+		dict += " "
+	}
+	pw.begin(ref)
+	pw.printf("<< %s/Filter /FlateDecode /Length %d >>\nstream\n", dict, buf.Len())
+	pw.write(buf.Bytes())
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// Close writes the cross reference table and the trailer,
+// with the catalog root and the document information info.
+func (pw *Writer) Close(root, info Ref) error {
+	for i, offset := range pw.offsets {
+		if offset < 0 {
+			return fmt.Errorf("object %d was allocated but not written", i+1)
+		}
+	}
+
+	xref := pw.offset
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf(
+		"trailer\n<< /Size %d /Root %s /Info %s >>\nstartxref\n%d\n%%%%EOF\n",
+		len(pw.offsets)+1, root, info, xref,
+	)
+
+	return pw.err
+}
+
+func (pw *Writer) begin(ref Ref) {
+	pw.offsets[ref-1] = pw.offset
+	pw.printf("%d 0 obj\n", ref)
+}
+
+func (pw *Writer) printf(format string, args ...any) {
+	pw.write(fmt.Appendf(nil, format, args...))
+}
+
+func (pw *Writer) write(p []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(p)
+	pw.offset += n
+	pw.err = err
+}
+
+// Num formats a number for PDF content, rounded to 4 decimals.
+// PDF has no exponent notation.
+func Num(v float64) string {
+	v = math.Round(v*1e4) / 1e4
+	if v == 0 {
+		// Avoid "-0"
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Text formats s as a PDF text string: a literal string for
+// printable ASCII, and UTF-16 with a byte order mark otherwise.
+func Text(s string) string {
+	ascii := strings.IndexFunc(s, func(c rune) bool {
+		return c < 0x20 || c >= 0x7f
+	}) < 0
+	if !ascii {
+		var b strings.Builder
+		b.WriteString("<FEFF")
+		for _, u := range utf16.Encode([]rune(s)) {
+			fmt.Fprintf(&b, "%04X", u)
+		}
+		b.WriteByte('>')
+		return b.String()
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	return "(" + r.Replace(s) + ")"
+}
+
+// Name formats s as a PDF name, escaping the delimiters, the number
+// sign and the bytes outside printable ASCII.
+func Name(s string) string {
+	var b strings.Builder
+	b.WriteByte('/')
+	for i := range len(s) {
+		c := s[i]
+		if c < 0x21 || c > 0x7e || strings.IndexByte("#()<>[]{}/%", c) >= 0 {
+			fmt.Fprintf(&b, "#%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
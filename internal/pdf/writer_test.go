@@ -0,0 +1,114 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var b bytes.Buffer
+	pw := NewWriter(&b)
+	catalog, pages, info, content := pw.Alloc(), pw.Alloc(), pw.Alloc(), pw.Alloc()
+	// Objects written out of order
+	pw.Stream(content, "", []byte("0 0 1 1 re f"))
+	pw.Object(info, "<< /Title "+Text("qrgen")+" >>")
+	pw.Object(pages, "<< /Type /Pages /Kids [] /Count 0 >>")
+	pw.Object(catalog, "<< /Type /Catalog /Pages "+pages.String()+" >>")
+	if err := pw.Close(catalog, info); err != nil {
+		t.Fatal(err)
+	}
+	data := b.String()
+
+	if !strings.HasPrefix(data, "%PDF-1.4\n") {
+		t.Errorf("got header %q, want %%PDF-1.4", data[:min(len(data), 9)])
+	}
+	m := regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R /Info 3 0 R >>\nstartxref\n(\d+)\n%%EOF\n$`).FindStringSubmatch(data)
+	if m == nil {
+		t.Fatalf("got trailer %q", data[strings.LastIndex(data, "xref"):])
+	}
+	if m[1] != "5" {
+		t.Errorf("got /Size %s, want 5", m[1])
+	}
+	xref, _ := strconv.Atoi(m[2])
+	if !strings.HasPrefix(data[xref:], "xref\n0 5\n0000000000 65535 f \n") {
+		t.Fatalf("startxref %d does not point at the cross reference table", xref)
+	}
+	entries := data[xref+len("xref\n0 5\n0000000000 65535 f \n"):]
+	for i := range 4 {
+		entry := entries[20*i : 20*i+20]
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil || entry[10:] != " 00000 n \n" {
+			t.Fatalf("got cross reference entry %q", entry)
+		}
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(data[offset:], want) {
+			t.Errorf("entry of object %d points at %q", i+1, data[offset:min(len(data), offset+10)])
+		}
+	}
+	if !strings.Contains(data, "4 0 obj\n<< /Filter /FlateDecode /Length ") {
+		t.Error("the stream is not compressed")
+	}
+}
+
+func TestWriterUnwritten(t *testing.T) {
+	var b bytes.Buffer
+	pw := NewWriter(&b)
+	catalog := pw.Alloc()
+	pw.Alloc()
+	pw.Object(catalog, "<< /Type /Catalog >>")
+	if err := pw.Close(catalog, catalog); err == nil || !strings.Contains(err.Error(), "object 2") {
+		t.Errorf("got error %v, want object 2 unwritten", err)
+	}
+}
+
+func TestNum(t *testing.T) {
+	for _, tc := range []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{-0.00001, "0"},
+		{1, "1"},
+		{-2.5, "-2.5"},
+		{1.23456, "1.2346"},
+		{1e7, "10000000"},
+	} {
+		if got := Num(tc.v); got != tc.want {
+			t.Errorf("Num(%g): got %s, want %s", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{"qrgen", "(qrgen)"},
+		{`a (b) \c`, `(a \(b\) \\c)`},
+		{"é", "<FEFF00E9>"},
+		{"😀", "<FEFFD83DDE00>"},
+		{"a\nb", "<FEFF0061000A0062>"},
+	} {
+		if got := Text(tc.s); got != tc.want {
+			t.Errorf("Text(%q): got %s, want %s", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{"PANTONE", "/PANTONE"},
+		{"PANTONE 185 C", "/PANTONE#20185#20C"},
+		{"a/b#c", "/a#2Fb#23c"},
+		{"é", "/#C3#A9"},
+	} {
+		if got := Name(tc.s); got != tc.want {
+			t.Errorf("Name(%q): got %s, want %s", tc.s, got, tc.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package qrcode
 
 import (
+	"image"
+
 	"github.com/ahmadnaufalhakim/qrgen/internal/encoder"
 	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
 	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
@@ -13,6 +15,8 @@ type QRBuilder struct {
 	minVersion int
 	ecLevel    qrconst.ErrorCorrectionLevel
 	maskNum    *int
+	trace      bool
+	padArt     image.Image
 }
 
 func NewQRBuilder(text string) *QRBuilder {
@@ -22,6 +26,7 @@ func NewQRBuilder(text string) *QRBuilder {
 		minVersion: 1,
 		ecLevel:    qrconst.M,
 		maskNum:    nil,
+		trace:      false,
 	}
 }
 
@@ -49,6 +54,13 @@ func (b *QRBuilder) WithMaskNum(maskNum *int) *QRBuilder {
 	return b
 }
 
+// WithTrace makes Build record every intermediate artifact of the
+// encoding pipeline into the Trace field of the resulting QR Code.
+func (b *QRBuilder) WithTrace(trace bool) *QRBuilder {
+	b.trace = trace
+	return b
+}
+
 func (b *QRBuilder) Build() (*QRCode, error) {
 	// 0. If text is empty, return a default (template) QR Code object
 	if b.text == "" {
@@ -113,47 +125,65 @@ func (b *QRBuilder) Build() (*QRCode, error) {
 		return nil, err
 	}
 
-	// 6. Assemble data blocks using the previously
-	// assembled data codewords
-	dataBlocks, err := qrencode.AssembleDataBlocks(
-		version,
-		b.ecLevel,
-		dataCodewords,
-	)
-	if err != nil {
-		return nil, err
-	}
+	// 6. Optionally draw the art with the pad codewords,
+	// which also determines the mask pattern
+	maskNum := b.maskNum
+	var artPenalties []matrix.MaskPenalty
+	if b.padArt != nil {
+		bits := 0
+		for _, bitString := range bitStrings {
+			bits += len(bitString)
+		}
 
-	// 7. Generate the error correction blocks for each data block
-	ecBlocks, err := qrencode.GenerateErrorCorrectionBlocks(
-		version,
-		b.ecLevel,
-		dataBlocks,
-	)
-	if err != nil {
-		return nil, err
+		var artMaskNum int
+		dataCodewords, artMaskNum, artPenalties, err = b.drawPadArt(version, bits, dataCodewords)
+		if err != nil {
+			return nil, err
+		}
+		maskNum = &artMaskNum
 	}
 
-	// 8. Interleave blocks
-	messageBitString, err := qrencode.InterleaveBlocks(
+	// 7. Assemble data blocks, generate the error correction
+	// blocks for each data block, and interleave them
+	dataBlocks, ecBlocks, messageBitString, err := encodeMessage(
 		version,
 		b.ecLevel,
-		dataBlocks,
-		ecBlocks,
+		dataCodewords,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// 9. Construct the QR Code object
+	// 8. Construct the QR Code object
 	qrCode := NewQRCode(
 		version,
 		b.ecLevel,
 		messageBitString,
 	)
+	qrCode.Text = b.text
+	qrCode.Mode = encoder.Mode()
 
-	// 10. Place modules in the QR Code matrix
-	err = b.placeAllModules(qrCode)
+	if b.trace {
+		qrCode.Trace = &Trace{
+			Text:               b.text,
+			Mode:               encoder.Mode(),
+			CharCount:          encoder.CharCount(),
+			Version:            version,
+			ECLevel:            b.ecLevel,
+			ModeIndicator:      bitStrings[0],
+			CharCountIndicator: bitStrings[1],
+			DataBits:           dataBits,
+			DataCodewords:      dataCodewords,
+			DataBlocks:         dataBlocks,
+			ECBlocks:           ecBlocks,
+			MessageBits:        messageBitString,
+			MaskPenalties:      artPenalties,
+			PadArt:             b.padArt != nil,
+		}
+	}
+
+	// 9. Place modules in the QR Code matrix
+	err = b.placeAllModules(qrCode, maskNum)
 	if err != nil {
 		return nil, err
 	}
@@ -194,30 +224,46 @@ func (b *QRBuilder) placeTemplateModules(qr *QRCode) error {
 	return nil
 }
 
-func (b *QRBuilder) placeFormatAndDataModules(qr *QRCode) error {
-	// Determine the mask pattern
-	if b.maskNum != nil {
-		qr.MaskNum = *b.maskNum
-	} else {
-		qr.MaskNum = matrix.DetermineBestMaskNum(
+func (b *QRBuilder) placeFormatAndDataModules(qr *QRCode, maskNum *int) error {
+	// Place the message bits first, so that the mask
+	// penalties are evaluated on the complete symbol
+	matrix.PlaceMessageBits(
+		qr.MessageBits,
+		qr.Modules,
+		qr.Patterns,
+	)
+
+	// Determine the mask pattern. The penalties of pad art are
+	// already traced, as its pad codewords depend on the mask.
+	var maskPenalties []matrix.MaskPenalty
+	if maskNum == nil || qr.Trace != nil && qr.Trace.MaskPenalties == nil {
+		maskPenalties = matrix.EvaluateMaskPatterns(
 			qr.ECLevel,
 			qr.Modules,
 			qr.Patterns,
 		)
 	}
+	if maskNum != nil {
+		qr.MaskNum = *maskNum
+	} else {
+		qr.MaskNum = matrix.BestMaskNum(maskPenalties)
+	}
 
-	// Place format information and message bits
+	if qr.Trace != nil {
+		if maskPenalties != nil {
+			qr.Trace.MaskPenalties = maskPenalties
+		}
+		qr.Trace.MaskNum = qr.MaskNum
+		qr.Trace.MaskForced = b.maskNum != nil
+	}
+
+	// Place format information and apply the mask pattern
 	matrix.PlaceFormatInformation(
 		qr.ECLevel,
 		qr.Modules,
 		qr.Patterns,
 		qr.MaskNum,
 	)
-	matrix.PlaceMessageBits(
-		qr.MessageBits,
-		qr.Modules,
-		qr.Patterns,
-	)
 	matrix.ApplyMaskPattern(
 		qr.MaskNum,
 		qr.Modules,
@@ -227,16 +273,55 @@ func (b *QRBuilder) placeFormatAndDataModules(qr *QRCode) error {
 	return nil
 }
 
-func (b *QRBuilder) placeAllModules(qr *QRCode) error {
+func (b *QRBuilder) placeAllModules(qr *QRCode, maskNum *int) error {
 	err := b.placeTemplateModules(qr)
 	if err != nil {
 		return err
 	}
 
-	err = b.placeFormatAndDataModules(qr)
+	err = b.placeFormatAndDataModules(qr, maskNum)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// encodeMessage assembles the data blocks of the data codewords,
+// generates their error correction blocks, and interleaves them
+// into the message bit string.
+func encodeMessage(
+	version int,
+	ecLevel qrconst.ErrorCorrectionLevel,
+	dataCodewords []string,
+) ([][]string, [][]uint8, string, error) {
+	dataBlocks, err := qrencode.AssembleDataBlocks(
+		version,
+		ecLevel,
+		dataCodewords,
+	)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	ecBlocks, err := qrencode.GenerateErrorCorrectionBlocks(
+		version,
+		ecLevel,
+		dataBlocks,
+	)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	messageBitString, err := qrencode.InterleaveBlocks(
+		version,
+		ecLevel,
+		dataBlocks,
+		ecBlocks,
+	)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return dataBlocks, ecBlocks, messageBitString, nil
+}
@@ -0,0 +1,109 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func moduleRows(modules [][]bool) []string {
+	rows := make([]string, len(modules))
+	for y, row := range modules {
+		var sb strings.Builder
+		for _, dark := range row {
+			if dark {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		rows[y] = sb.String()
+	}
+	return rows
+}
+
+func cloneModules(modules [][]bool) [][]bool {
+	clone := make([][]bool, len(modules))
+	for y, row := range modules {
+		clone[y] = append([]bool(nil), row...)
+	}
+	return clone
+}
+
+func TestBuildKnownSymbol(t *testing.T) {
+	// "HELLO WORLD" at level Q is the usual worked example, with the
+	// data codewords 20 5B 0B 78 D1 72 DC 4D 43 40 EC 11 EC and the
+	// error correction codewords A8 48 16 52 D9 36 9C 00 2E 0F B4 7A 10
+	want := []string{
+		"#######....#..#######",
+		"#.....#.##..#.#.....#",
+		"#.###.#..#.##.#.###.#",
+		"#.###.#.#####.#.###.#",
+		"#.###.#.##.#..#.###.#",
+		"#.....#..#..#.#.....#",
+		"#######.#.#.#.#######",
+		"........##.##........",
+		".#.####.##..###.##.#.",
+		"#.####.#....####.###.",
+		"..#.#.##...#..##.....",
+		"#.##.#...#.##...##...",
+		"##.########.###.#####",
+		"........#...#..#.#...",
+		"#######..##..##..####",
+		"#.....#.#.#..#..#.###",
+		"#.###.#.##.#..#...###",
+		"#.###.#.#.###...#.#..",
+		"#.###.#..#....#....##",
+		"#.....#.###..###..##.",
+		"#######..#.#.......#.",
+	}
+
+	qr, err := NewQRBuilder("HELLO WORLD").
+		WithErrorCorrectionLevel(qrconst.Q).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qr.Version != 1 || qr.MaskNum != 6 {
+		t.Fatalf("got version %d mask %d, want version 1 mask 6", qr.Version, qr.MaskNum)
+	}
+	for y, row := range moduleRows(qr.Modules) {
+		if row != want[y] {
+			t.Errorf("row %d: got %s, want %s", y, row, want[y])
+		}
+	}
+}
+
+func TestBuildLowestPenaltyMask(t *testing.T) {
+	for _, text := range []string{
+		"HELLO WORLD",
+		"01234567",
+		"qrgen",
+		"https://example.com/some/longer/path?with=query",
+		strings.Repeat("0123456789", 30),
+	} {
+		qr, err := NewQRBuilder(text).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Unmask the symbol, then score it with every mask
+		unmasked := cloneModules(qr.Modules)
+		matrix.ApplyMaskPattern(qr.MaskNum, unmasked, qr.Patterns)
+		bestMaskNum, bestPenalty := -1, int(^uint(0)>>1)
+		for maskNum := range 8 {
+			modules := cloneModules(unmasked)
+			matrix.ApplyMaskPattern(maskNum, modules, qr.Patterns)
+			matrix.PlaceFormatInformation(qr.ECLevel, modules, qr.Patterns, maskNum)
+			if penalty := matrix.TotalPenalty(modules); penalty < bestPenalty {
+				bestMaskNum, bestPenalty = maskNum, penalty
+			}
+		}
+
+		if qr.MaskNum != bestMaskNum {
+			t.Errorf("%q: got mask %d, want mask %d of the lowest penalty", text, qr.MaskNum, bestMaskNum)
+		}
+	}
+}
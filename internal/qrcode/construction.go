@@ -0,0 +1,124 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// ConstructionStep is a snapshot of the QR Code matrix taken after one
+// step of the module placement.
+type ConstructionStep struct {
+	Name     string
+	Modules  [][]bool
+	Patterns [][]qrconst.FunctionPattern
+
+	// Placed holds the {row, col} positions of the modules
+	// that were placed (or changed) during this step.
+	Placed [][2]int
+}
+
+// ConstructionSteps replays the module placement of a built QR Code and
+// returns a snapshot after every step: finder patterns, separators,
+// alignment patterns, timing patterns, dark module, version information,
+// format information reservation, the zigzag placement of the message
+// bits (bitsPerStep bits at a time), format information and finally
+// the mask pattern.
+func ConstructionSteps(qr QRCode, bitsPerStep int) []ConstructionStep {
+	if bitsPerStep < 1 {
+		bitsPerStep = 1
+	}
+
+	replay := NewQRCode(qr.Version, qr.ECLevel, qr.MessageBits)
+	modules, patterns := replay.Modules, replay.Patterns
+
+	var steps []ConstructionStep
+	snapshot := func(name string, place func()) {
+		prevModules := copyModules(modules)
+		prevPatterns := copyPatterns(patterns)
+		place()
+
+		var placed [][2]int
+		for i := range modules {
+			for j := range modules[i] {
+				if modules[i][j] != prevModules[i][j] ||
+					patterns[i][j] != prevPatterns[i][j] {
+					placed = append(placed, [2]int{i, j})
+				}
+			}
+		}
+
+		steps = append(steps, ConstructionStep{
+			Name:     name,
+			Modules:  copyModules(modules),
+			Patterns: copyPatterns(patterns),
+			Placed:   placed,
+		})
+	}
+
+	snapshot("Finder patterns", func() {
+		matrix.PlaceFinderPatterns(modules, patterns)
+	})
+	snapshot("Separators", func() {
+		matrix.PlaceSeparators(modules, patterns)
+	})
+	snapshot("Alignment patterns", func() {
+		matrix.PlaceAlignmentPattern(modules, patterns)
+	})
+	snapshot("Timing patterns", func() {
+		matrix.PlaceTimingPattern(modules, patterns)
+	})
+	snapshot("Dark module", func() {
+		matrix.PlaceDarkModule(modules, patterns)
+	})
+	if qr.Version >= 7 {
+		snapshot("Version information", func() {
+			matrix.PlaceVersionInformation(modules, patterns)
+		})
+	}
+	snapshot("Format information area", func() {
+		matrix.ReserveFormatInformationArea(patterns)
+	})
+
+	// Template QR Codes carry no message
+	if qr.MessageBits == "" {
+		return steps
+	}
+
+	positions := matrix.MessageBitPositions(patterns)
+	for start := 0; start < len(positions); start += bitsPerStep {
+		end := min(start+bitsPerStep, len(positions))
+		snapshot(fmt.Sprintf("Message bits %d-%d of %d", start+1, end, len(positions)), func() {
+			for idx, pos := range positions[start:end] {
+				modules[pos[0]][pos[1]] = qr.MessageBits[start+idx] == '1'
+				patterns[pos[0]][pos[1]] = qrconst.FPMessageBit
+			}
+		})
+	}
+
+	snapshot("Format information", func() {
+		matrix.PlaceFormatInformation(qr.ECLevel, modules, patterns, qr.MaskNum)
+	})
+	snapshot(fmt.Sprintf("Mask pattern %d", qr.MaskNum), func() {
+		matrix.ApplyMaskPattern(qr.MaskNum, modules, patterns)
+	})
+
+	return steps
+}
+
+func copyModules(src [][]bool) [][]bool {
+	dst := make([][]bool, len(src))
+	for i := range src {
+		dst[i] = append([]bool(nil), src[i]...)
+	}
+	return dst
+}
+
+func copyPatterns(src [][]qrconst.FunctionPattern) [][]qrconst.FunctionPattern {
+	dst := make([][]qrconst.FunctionPattern, len(src))
+	for i := range src {
+		dst[i] = append([]qrconst.FunctionPattern(nil), src[i]...)
+	}
+	return dst
+}
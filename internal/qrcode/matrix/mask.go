@@ -5,22 +5,67 @@ import (
 	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
 )
 
-func DetermineBestMaskNum(
+// MaskPenalty holds the score of each of the four penalty rules
+// for a single mask pattern.
+type MaskPenalty struct {
+	MaskNum       int
+	RunLength     int
+	BlockPattern  int
+	FinderPattern int
+	DarkAndLight  int
+}
+
+// Total returns the sum of all four penalty rules.
+func (mp MaskPenalty) Total() int {
+	return mp.RunLength +
+		mp.BlockPattern +
+		mp.FinderPattern +
+		mp.DarkAndLight
+}
+
+// EvaluateMaskPatterns applies each of the eight mask patterns to a copy
+// of the modules (together with the matching format information) and
+// returns the penalty breakdown for every mask, indexed by mask number.
+func EvaluateMaskPatterns(
 	ecLevel qrconst.ErrorCorrectionLevel,
 	modules [][]bool,
 	patterns [][]qrconst.FunctionPattern,
-) int {
-	bestMaskNum := 0
-	bestPenalty := int(^uint(0) >> 1)
+) []MaskPenalty {
+	penalties := make([]MaskPenalty, len(tables.MaskPatterns))
 	for maskNum := range tables.MaskPatterns {
 		maskedModules := copyModules(modules)
 		ApplyMaskPattern(maskNum, maskedModules, patterns)
 		PlaceFormatInformation(ecLevel, maskedModules, patterns, maskNum)
 
-		penalty := TotalPenalty(maskedModules)
-		if penalty < bestPenalty {
-			bestMaskNum = maskNum
-			bestPenalty = penalty
+		penalties[maskNum] = MaskPenalty{
+			MaskNum:       maskNum,
+			RunLength:     PenaltyRunLength(maskedModules),
+			BlockPattern:  PenaltyBlockPattern(maskedModules),
+			FinderPattern: PenaltyFinderPattern(maskedModules),
+			DarkAndLight:  PenaltyDarkAndLightModules(maskedModules),
+		}
+	}
+
+	return penalties
+}
+
+func DetermineBestMaskNum(
+	ecLevel qrconst.ErrorCorrectionLevel,
+	modules [][]bool,
+	patterns [][]qrconst.FunctionPattern,
+) int {
+	return BestMaskNum(EvaluateMaskPatterns(ecLevel, modules, patterns))
+}
+
+// BestMaskNum returns the mask number with the lowest total penalty.
+// Ties are resolved in favor of the lower mask number.
+func BestMaskNum(penalties []MaskPenalty) int {
+	bestMaskNum := 0
+	bestPenalty := int(^uint(0) >> 1)
+	for _, penalty := range penalties {
+		if penalty.Total() < bestPenalty {
+			bestMaskNum = penalty.MaskNum
+			bestPenalty = penalty.Total()
 		}
 	}
 
@@ -181,10 +181,21 @@ func PlaceMessageBits(
 	modules [][]bool,
 	patterns [][]qrconst.FunctionPattern,
 ) {
-	size := len(modules)
+	for msgBitIdx, pos := range MessageBitPositions(patterns) {
+		modules[pos[0]][pos[1]] = messageBits[msgBitIdx] == '1'
+		patterns[pos[0]][pos[1]] = qrconst.FPMessageBit
+	}
+}
 
+// MessageBitPositions returns the {row, col} position of every message bit
+// in placement order, following the two-module wide zigzag that runs
+// upward and downward from the bottom-right corner of the symbol.
+// Positions taken by function patterns are skipped.
+func MessageBitPositions(patterns [][]qrconst.FunctionPattern) [][2]int {
+	size := len(patterns)
+
+	var positions [][2]int
 	upward := true
-	msgBitIdx := 0
 
 	// Calculate both row and column based on given index,
 	// upward condition, and column.
@@ -207,15 +218,16 @@ func PlaceMessageBits(
 		}
 
 		for idx := range 2 * size {
-			if patterns[row(idx, upward)][col(idx, j)].IsUnoccupied() {
-				modules[row(idx, upward)][col(idx, j)] = messageBits[msgBitIdx] == '1'
-				patterns[row(idx, upward)][col(idx, j)] = qrconst.FPMessageBit
-				msgBitIdx++
+			pattern := patterns[row(idx, upward)][col(idx, j)]
+			if pattern.IsUnoccupied() || pattern.IsMessage() {
+				positions = append(positions, [2]int{row(idx, upward), col(idx, j)})
 			}
 		}
 
 		upward = !upward
 	}
+
+	return positions
 }
 
 func ReserveFormatInformationArea(patterns [][]qrconst.FunctionPattern) {
@@ -0,0 +1,157 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	xdraw "golang.org/x/image/draw"
+)
+
+// WithPadArt draws the image, scaled to the symbol, with the pad
+// codewords: they are chosen so that their modules, once masked, are
+// dark where the image is dark, and the mask of the lowest penalty
+// with the art drawn is applied, unless forced. Readers ignore the pad
+// codewords, so the symbol keeps its content. Only the modules of the
+// pad codewords show the art, so a higher minimum version or a lower
+// error correction level shows more of it. A nil image removes it.
+func (b *QRBuilder) WithPadArt(img image.Image) *QRBuilder {
+	b.padArt = img
+	return b
+}
+
+// drawPadArt returns the data codewords with the pad codewords drawing
+// the art, and the mask to apply. The data takes bits bits, before its
+// terminator. Unless the mask is forced, it also returns the penalties
+// of every mask, with the pad codewords drawing the art for that mask.
+func (b *QRBuilder) drawPadArt(
+	version int,
+	bits int,
+	dataCodewords []string,
+) ([]string, int, []matrix.MaskPenalty, error) {
+	template := NewQRCode(version, b.ecLevel, "")
+	if err := b.placeTemplateModules(template); err != nil {
+		return nil, 0, nil, err
+	}
+	positions := matrix.MessageBitPositions(template.Patterns)
+	order := interleavedOrder(version, b.ecLevel)
+	target := padArtModules(b.padArt, template.Size)
+
+	// Like Trace.PadCodewords, the terminator and the bit padding are
+	// kept, and the following codewords are free
+	used := (min(bits+4, len(dataCodewords)*8) + 7) / 8
+
+	masks := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	if b.maskNum != nil {
+		masks = []int{*b.maskNum}
+	}
+
+	var (
+		codewordsByMask [][]string
+		penalties       []matrix.MaskPenalty
+	)
+	for _, maskNum := range masks {
+		// The mask flips the bits of the modules it covers,
+		// so the bits are the target flipped by the mask
+		maskPattern := tables.MaskPatterns[maskNum]
+		codewords := slices.Clone(dataCodewords)
+		for k := used; k < len(codewords); k++ {
+			codeword := []byte("00000000")
+			for i := range codeword {
+				pos := positions[8*order[k]+i]
+				if target[pos[0]][pos[1]] != maskPattern(pos[0], pos[1]) {
+					codeword[i] = '1'
+				}
+			}
+			codewords[k] = string(codeword)
+		}
+		if len(masks) == 1 {
+			return codewords, maskNum, nil, nil
+		}
+
+		_, _, messageBits, err := encodeMessage(version, b.ecLevel, codewords)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		qr := NewQRCode(version, b.ecLevel, messageBits)
+		if err := b.placeTemplateModules(qr); err != nil {
+			return nil, 0, nil, err
+		}
+		matrix.PlaceMessageBits(qr.MessageBits, qr.Modules, qr.Patterns)
+		matrix.PlaceFormatInformation(qr.ECLevel, qr.Modules, qr.Patterns, maskNum)
+		matrix.ApplyMaskPattern(maskNum, qr.Modules, qr.Patterns)
+
+		codewordsByMask = append(codewordsByMask, codewords)
+		penalties = append(penalties, matrix.MaskPenalty{
+			MaskNum:       maskNum,
+			RunLength:     matrix.PenaltyRunLength(qr.Modules),
+			BlockPattern:  matrix.PenaltyBlockPattern(qr.Modules),
+			FinderPattern: matrix.PenaltyFinderPattern(qr.Modules),
+			DarkAndLight:  matrix.PenaltyDarkAndLightModules(qr.Modules),
+		})
+	}
+
+	maskNum := matrix.BestMaskNum(penalties)
+	return codewordsByMask[maskNum], maskNum, penalties, nil
+}
+
+// interleavedOrder returns the position of every data
+// codeword in the interleaved message.
+func interleavedOrder(version int, ecLevel qrconst.ErrorCorrectionLevel) []int {
+	ecBlockInfo := tables.ECBlockInfos[ecLevel][version-1]
+	var blockSizes []int
+	for range ecBlockInfo.Group1Blocks {
+		blockSizes = append(blockSizes, ecBlockInfo.Group1DataCodewordsPerBlock)
+	}
+	for range ecBlockInfo.Group2Blocks {
+		blockSizes = append(blockSizes, ecBlockInfo.Group2DataCodewordsPerBlock)
+	}
+
+	// The codewords are taken by column, across the blocks
+	order := make([]int, ecBlockInfo.Group1Blocks*ecBlockInfo.Group1DataCodewordsPerBlock+
+		ecBlockInfo.Group2Blocks*ecBlockInfo.Group2DataCodewordsPerBlock)
+	pos := 0
+	for j := range slices.Max(blockSizes) {
+		start := 0
+		for _, size := range blockSizes {
+			if j < size {
+				order[start+j] = pos
+				pos++
+			}
+			start += size
+		}
+	}
+
+	return order
+}
+
+// padArtModules scales the image to size by size modules,
+// which are dark where its luminance over white is dark.
+func padArtModules(img image.Image, size int) [][]bool {
+	scaled := image.NewRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	modules := make([][]bool, size)
+	for y := range size {
+		modules[y] = make([]bool, size)
+		for x := range size {
+			modules[y][x] = LuminanceOverWhite(scaled.RGBAAt(x, y)) < 128
+		}
+	}
+
+	return modules
+}
+
+// LuminanceOverWhite returns the luminance, from 0 to 255, of the
+// premultiplied color over white, e.g. of a pixel of art drawn with
+// the modules.
+func LuminanceOverWhite(c color.RGBA) float64 {
+	// Premultiplied, so transparent pixels add the white underneath.
+	// The weights are integers, so that mid-gray is exactly 128.
+	white := 255 - int(c.A)
+	return float64(299*(int(c.R)+white)+587*(int(c.G)+white)+114*(int(c.B)+white)) / 1000
+}
@@ -0,0 +1,288 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// heartArt returns a black heart on white.
+func heartArt() image.Image {
+	const size = 200
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			fx, fy := float64(x-size/2)/70, float64(size/2-y)/70+.2
+			v := fx*fx + fy*fy - 1
+			if v*v*v-fx*fx*fy*fy*fy < 0 {
+				img.SetGray(x, y, color.Gray{0})
+			} else {
+				img.SetGray(x, y, color.Gray{255})
+			}
+		}
+	}
+	return img
+}
+
+func TestTracePadArt(t *testing.T) {
+	art := heartArt()
+	for _, text := range []string{"hi", "hello", "abc", "x", "qrgen"} {
+		qr, err := NewQRBuilder(text).
+			WithMinVersion(3).
+			WithPadArt(art).
+			WithTrace(true).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := qr.Trace
+
+		if tr.MaskForced || !tr.PadArt || tr.MaskNum != qr.MaskNum {
+			t.Errorf("%q: got mask %d forced %v pad art %v, want mask %d chosen for the art", text, tr.MaskNum, tr.MaskForced, tr.PadArt, qr.MaskNum)
+		}
+		if len(tr.MaskPenalties) != 8 {
+			t.Fatalf("%q: got %d mask penalties, want 8", text, len(tr.MaskPenalties))
+		}
+		if best := matrix.BestMaskNum(tr.MaskPenalties); best != qr.MaskNum {
+			t.Errorf("%q: got mask %d, but mask %d has the lowest traced penalty", text, qr.MaskNum, best)
+		}
+		// The traced penalty of the chosen mask is of the final symbol
+		if got, want := tr.MaskPenalties[qr.MaskNum].Total(), matrix.TotalPenalty(qr.Modules); got != want {
+			t.Errorf("%q: got traced penalty %d of mask %d, want %d of the symbol", text, got, qr.MaskNum, want)
+		}
+	}
+}
+
+func TestTracePadArtForcedMask(t *testing.T) {
+	maskNum := 5
+	qr, err := NewQRBuilder("hi").
+		WithMinVersion(3).
+		WithErrorCorrectionLevel(qrconst.Q).
+		WithMaskNum(&maskNum).
+		WithPadArt(heartArt()).
+		WithTrace(true).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qr.MaskNum != 5 || !qr.Trace.MaskForced {
+		t.Errorf("got mask %d forced %v, want forced mask 5", qr.MaskNum, qr.Trace.MaskForced)
+	}
+	if len(qr.Trace.MaskPenalties) != 8 {
+		t.Errorf("got %d mask penalties, want all 8", len(qr.Trace.MaskPenalties))
+	}
+}
+
+// formatBits returns the 15 format information bits
+// of the level and the mask, most significant first.
+func formatBits(ecLevel qrconst.ErrorCorrectionLevel, maskNum int) []bool {
+	levelBits := map[qrconst.ErrorCorrectionLevel]int{qrconst.L: 1, qrconst.M: 0, qrconst.Q: 3, qrconst.H: 2}
+	data := levelBits[ecLevel]<<3 | maskNum
+	// BCH(15, 5) code of the generator x^10+x^8+x^5+x^4+x^2+x+1
+	rem := data << 10
+	for i := 14; i >= 10; i-- {
+		if rem&(1<<i) != 0 {
+			rem ^= 0x537 << (i - 10)
+		}
+	}
+	code := (data<<10 | rem) ^ 0x5412
+
+	bits := make([]bool, 15)
+	for i := range bits {
+		bits[i] = code&(1<<(14-i)) != 0
+	}
+	return bits
+}
+
+// rsSyndromesZero reports whether the codewords, data then error
+// correction, are a Reed-Solomon codeword of GF(256) modulo 0x11d.
+func rsSyndromesZero(codewords []uint8, ecLen int) bool {
+	var exp [512]uint8
+	var log [256]int
+	x := 1
+	for i := range 255 {
+		exp[i], exp[i+255] = uint8(x), uint8(x)
+		log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+
+	for i := range ecLen {
+		var s uint8
+		for _, c := range codewords {
+			if s != 0 {
+				s = exp[log[s]+i]
+			}
+			s ^= c
+		}
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPadArt(t *testing.T) {
+	art := heartArt()
+	forced := 4
+	for _, tc := range []struct {
+		text    string
+		version int
+		ecLevel qrconst.ErrorCorrectionLevel
+		maskNum *int
+	}{
+		{"HI", 5, qrconst.L, nil},
+		{"https://example.com", 10, qrconst.M, nil},
+		{"12345", 7, qrconst.H, nil},
+		{"x", 2, qrconst.Q, &forced},
+		{"byte data ü", 20, qrconst.L, nil},
+	} {
+		build := func(art image.Image) *QRCode {
+			qr, err := NewQRBuilder(tc.text).
+				WithMinVersion(tc.version).
+				WithErrorCorrectionLevel(tc.ecLevel).
+				WithMaskNum(tc.maskNum).
+				WithPadArt(art).
+				WithTrace(true).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return qr
+		}
+		plain, qr := build(nil), build(art)
+		tr := qr.Trace
+		if qr.Version != tc.version || (tc.maskNum != nil && qr.MaskNum != *tc.maskNum) {
+			t.Fatalf("%q: got version %d mask %d", tc.text, qr.Version, qr.MaskNum)
+		}
+
+		// Both copies of the format information
+		want := formatBits(tc.ecLevel, qr.MaskNum)
+		n := qr.Size
+		var copy1, copy2 []bool
+		for _, p := range [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}} {
+			copy1 = append(copy1, qr.Modules[p[0]][p[1]])
+		}
+		for i := range 7 {
+			copy2 = append(copy2, qr.Modules[n-1-i][8])
+		}
+		for i := range 8 {
+			copy2 = append(copy2, qr.Modules[8][n-8+i])
+		}
+		for i := range want {
+			if copy1[i] != want[i] || copy2[i] != want[i] {
+				t.Errorf("%q: format information bit %d differs from level %v mask %d", tc.text, i, tc.ecLevel, qr.MaskNum)
+				break
+			}
+		}
+
+		// The unmasked symbol holds the message bits
+		unmasked := cloneModules(qr.Modules)
+		matrix.ApplyMaskPattern(qr.MaskNum, unmasked, qr.Patterns)
+		positions := matrix.MessageBitPositions(qr.Patterns)
+		for i, p := range positions {
+			if unmasked[p[0]][p[1]] != (qr.MessageBits[i] == '1') {
+				t.Fatalf("%q: message bit %d is not placed", tc.text, i)
+			}
+		}
+
+		// The message interleaves the blocks, which are Reed-Solomon codewords
+		var interleaved []uint8
+		for _, blocks := range [][][]uint8{codewordBlocks(t, tr.DataBlocks), tr.ECBlocks} {
+			for j := 0; ; j++ {
+				more := false
+				for _, block := range blocks {
+					if j < len(block) {
+						interleaved = append(interleaved, block[j])
+						more = true
+					}
+				}
+				if !more {
+					break
+				}
+			}
+		}
+		for i, c := range interleaved {
+			if got := codewordByte(t, qr.MessageBits[8*i:8*i+8]); got != c {
+				t.Fatalf("%q: message codeword %d is %d, want %d", tc.text, i, got, c)
+			}
+		}
+		for i, block := range codewordBlocks(t, tr.DataBlocks) {
+			if !rsSyndromesZero(append(block, tr.ECBlocks[i]...), len(tr.ECBlocks[i])) {
+				t.Errorf("%q: block %d has nonzero syndromes", tc.text, i)
+			}
+		}
+
+		// The codewords before the pad codewords are unchanged
+		pads := tr.PadCodewords()
+		used := len(tr.DataCodewords) - pads
+		if pads == 0 || plain.Trace.PadCodewords() != pads {
+			t.Fatalf("%q: got %d pad codewords, want as many as the plain symbol, %d", tc.text, pads, plain.Trace.PadCodewords())
+		}
+		for k := range used {
+			if tr.DataCodewords[k] != plain.Trace.DataCodewords[k] {
+				t.Errorf("%q: data codeword %d changed from %s to %s", tc.text, k, plain.Trace.DataCodewords[k], tr.DataCodewords[k])
+			}
+		}
+
+		// The modules of the pad codewords draw the art
+		target := padArtModules(art, qr.Size)
+		order := interleavedOrder(qr.Version, tc.ecLevel)
+		for k := used; k < len(tr.DataCodewords); k++ {
+			for i := range 8 {
+				p := positions[8*order[k]+i]
+				if qr.Modules[p[0]][p[1]] != target[p[0]][p[1]] {
+					t.Fatalf("%q: module (%d, %d) of pad codeword %d does not draw the art", tc.text, p[1], p[0], k)
+				}
+			}
+		}
+	}
+}
+
+func codewordByte(t *testing.T, bits string) uint8 {
+	t.Helper()
+
+	v, err := strconv.ParseUint(bits, 2, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint8(v)
+}
+
+func codewordBlocks(t *testing.T, blocks [][]string) [][]uint8 {
+	t.Helper()
+
+	bytes := make([][]uint8, len(blocks))
+	for i, block := range blocks {
+		for _, codeword := range block {
+			bytes[i] = append(bytes[i], codewordByte(t, codeword))
+		}
+	}
+	return bytes
+}
+
+func TestLuminanceOverWhite(t *testing.T) {
+	for _, tc := range []struct {
+		c    color.RGBA
+		want float64
+	}{
+		{color.RGBA{0, 0, 0, 255}, 0},
+		{color.RGBA{255, 255, 255, 255}, 255},
+		{color.RGBA{128, 128, 128, 255}, 128},
+		{color.RGBA{}, 255},
+		// Half transparent black, premultiplied
+		{color.RGBA{0, 0, 0, 128}, 127},
+		{color.RGBA{255, 0, 0, 255}, 76.245},
+	} {
+		if got := LuminanceOverWhite(tc.c); got != tc.want {
+			t.Errorf("%v: got luminance %g, want %g", tc.c, got, tc.want)
+		}
+	}
+}
@@ -5,6 +5,10 @@ import (
 )
 
 type QRCode struct {
+	// Text is the encoded payload, and Mode its encoding
+	// mode, both unset for template symbols
+	Text        string
+	Mode        qrconst.EncodingMode
 	Version     int
 	ECLevel     qrconst.ErrorCorrectionLevel
 	Size        int
@@ -12,6 +16,7 @@ type QRCode struct {
 	Modules     [][]bool
 	Patterns    [][]qrconst.FunctionPattern
 	MaskNum     int
+	Trace       *Trace
 }
 
 func NewQRCode(
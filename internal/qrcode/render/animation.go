@@ -0,0 +1,264 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+)
+
+// AnimationOptions controls how the construction of a QR Code
+// is turned into frames.
+type AnimationOptions struct {
+	// BitsPerFrame is the number of message bits placed per frame
+	// during the zigzag placement.
+	BitsPerFrame int
+	// Delay is the time between frames, in 100ths of a second.
+	Delay int
+	// FinalDelay is the time the last frame is shown before the
+	// animation loops, in 100ths of a second.
+	FinalDelay int
+	// PlaceholderColor tints the modules that are not placed yet.
+	PlaceholderColor color.NRGBA
+	// HighlightColor tints the modules placed in the current frame.
+	HighlightColor color.NRGBA
+}
+
+// ConstructionFrame is a rendered construction step.
+type ConstructionFrame struct {
+	Step  string
+	Image image.Image
+}
+
+func DefaultAnimationOptions() AnimationOptions {
+	return AnimationOptions{
+		BitsPerFrame:     16,
+		Delay:            15,
+		FinalDelay:       300,
+		PlaceholderColor: color.NRGBA{128, 128, 128, 96},
+		HighlightColor:   color.NRGBA{229, 57, 53, 144},
+	}
+}
+
+// RenderConstructionFrames renders every construction step of the QR Code
+// with the renderer's styling: finder patterns, separators, alignment and
+// timing patterns, format reservation, zigzag data placement and masking.
+func (r *QRRenderer) RenderConstructionFrames(
+	qr qrcode.QRCode,
+	opts AnimationOptions,
+) []ConstructionFrame {
+	steps := qrcode.ConstructionSteps(qr, opts.BitsPerFrame)
+	scale, margin, _ := r.layout(qr)
+
+	frames := make([]ConstructionFrame, len(steps))
+	for i, step := range steps {
+		stepQR := qr
+		stepQR.Modules = step.Modules
+		stepQR.Patterns = step.Patterns
+
+		img := r.renderImage(stepQR).(*image.RGBA)
+
+		// Tint the modules that are still empty
+		placeholder := image.NewUniform(opts.PlaceholderColor)
+		for y, row := range step.Patterns {
+			for x, pattern := range row {
+				if pattern.IsUnoccupied() {
+					draw.Draw(img, moduleRect(x, y, scale, margin), placeholder, image.Point{}, draw.Over)
+				}
+			}
+		}
+
+		// Highlight the modules placed in this step
+		highlight := image.NewUniform(opts.HighlightColor)
+		for _, pos := range step.Placed {
+			draw.Draw(img, moduleRect(pos[1], pos[0], scale, margin), highlight, image.Point{}, draw.Over)
+		}
+
+		frames[i] = ConstructionFrame{
+			Step:  step.Name,
+			Image: img,
+		}
+	}
+
+	return frames
+}
+
+// RenderConstructionGIF writes the construction steps of the QR Code
+// as a looping animated GIF. Only the changed area of each frame is
+// encoded after the first one.
+func (r *QRRenderer) RenderConstructionGIF(
+	qr qrcode.QRCode,
+	w io.Writer,
+	opts AnimationOptions,
+) error {
+	frames := r.RenderConstructionFrames(qr, opts)
+	if len(frames) == 0 {
+		return nil
+	}
+
+	quantizer := newPaletteQuantizer(
+		r.paletteBases(),
+		opts.PlaceholderColor,
+		opts.HighlightColor,
+	)
+
+	anim := &gif.GIF{}
+	var prev *image.RGBA
+	for i, frame := range frames {
+		img := frame.Image.(*image.RGBA)
+
+		bounds := img.Bounds()
+		if prev != nil {
+			bounds = changedBounds(prev, img)
+			if bounds.Empty() {
+				// Keep the frame timing even if nothing changed
+				bounds = image.Rect(0, 0, 1, 1)
+			}
+		}
+
+		delay := opts.Delay
+		if i == len(frames)-1 {
+			delay = opts.FinalDelay
+		}
+
+		anim.Image = append(anim.Image, quantizer.quantize(img, bounds))
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+		prev = img
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+func moduleRect(x, y, scale, margin int) image.Rectangle {
+	return image.Rect(
+		margin+x*scale, margin+y*scale,
+		margin+(x+1)*scale, margin+(y+1)*scale,
+	)
+}
+
+// changedBounds returns the smallest rectangle containing
+// every pixel that differs between a and b.
+func changedBounds(a, b *image.RGBA) image.Rectangle {
+	changed := image.Rectangle{}
+	bounds := b.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if a.RGBAAt(x, y) != b.RGBAAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	return changed
+}
+
+// paletteBases returns the (premultiplied) background and foreground
+// color pairs to build the animation palette from. Gradients are
+// sampled at a few positions along their stops.
+func (r *QRRenderer) paletteBases() [][2]color.RGBA {
+	const samples = 8
+
+	if r.backgroundPaint == nil && r.foregroundPaint == nil {
+		return [][2]color.RGBA{{
+			color.RGBAModel.Convert(r.backgroundColor).(color.RGBA),
+			color.RGBAModel.Convert(r.foregroundColor).(color.RGBA),
+		}}
+	}
+
+	sample := func(c color.NRGBA, p *gradientPaint, t float64) color.RGBA {
+		if p != nil {
+			c = p.colorAt(t)
+		}
+		return color.RGBAModel.Convert(c).(color.RGBA)
+	}
+
+	bases := make([][2]color.RGBA, samples)
+	for i := range bases {
+		t := float64(i) / float64(samples-1)
+		bases[i] = [2]color.RGBA{
+			sample(r.backgroundColor, r.backgroundPaint, t),
+			sample(r.foregroundColor, r.foregroundPaint, t),
+		}
+	}
+
+	return bases
+}
+
+// paletteQuantizer maps the colors of rendered frames onto a fixed
+// palette made of blends between pairs of (premultiplied) background
+// and foreground colors, and the tint colors, memoizing the nearest
+// palette entry of every color.
+type paletteQuantizer struct {
+	palette color.Palette
+	cache   map[color.RGBA]uint8
+}
+
+func newPaletteQuantizer(bases [][2]color.RGBA, placeholder, highlight color.NRGBA) *paletteQuantizer {
+	// Keep the palette within the 256 colors of a GIF frame
+	steps := max(2, min(48, 85/len(bases)))
+
+	var palette color.Palette
+	for _, tint := range []color.NRGBA{{}, placeholder, highlight} {
+		for _, base := range bases {
+			for i := range steps {
+				blend := lerpRGBA(base[0], base[1], float64(i)/float64(steps-1))
+				palette = append(palette, overRGBA(blend, tint))
+			}
+		}
+	}
+
+	return &paletteQuantizer{
+		palette: palette,
+		cache:   make(map[color.RGBA]uint8),
+	}
+}
+
+func (q *paletteQuantizer) quantize(img *image.RGBA, bounds image.Rectangle) *image.Paletted {
+	paletted := image.NewPaletted(bounds, q.palette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			idx, ok := q.cache[c]
+			if !ok {
+				idx = uint8(q.palette.Index(c))
+				q.cache[c] = idx
+			}
+			paletted.SetColorIndex(x, y, idx)
+		}
+	}
+
+	return paletted
+}
+
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + .5)
+	}
+
+	return color.RGBA{
+		lerp(a.R, b.R),
+		lerp(a.G, b.G),
+		lerp(a.B, b.B),
+		lerp(a.A, b.A),
+	}
+}
+
+// overRGBA composites the straight alpha color src
+// over the premultiplied color dst.
+func overRGBA(dst color.RGBA, src color.NRGBA) color.RGBA {
+	a := float64(src.A) / 255
+	blend := func(d, s uint8) uint8 {
+		return uint8(float64(d)*(1-a) + float64(s)*a + .5)
+	}
+
+	return color.RGBA{
+		blend(dst.R, src.R),
+		blend(dst.G, src.G),
+		blend(dst.B, src.B),
+		blend(dst.A, 255),
+	}
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// MinContrastRatio is the lowest contrast ratio between the dark and
+// light modules that CheckContrast accepts. Below it, scanners start to
+// fail to tell the modules apart.
+const MinContrastRatio = 3.0
+
+// ContrastRatio returns the WCAG contrast ratio between two opaque
+// colors, from 1 (no contrast) to 21 (black on white). The alpha of
+// the colors is ignored.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + .05) / (lb + .05)
+}
+
+// WorstContrastRatio returns the lowest contrast ratio between a dark
+// and a light color, over every pair of gradient stops (or solid
+// colors). The dark colors are the foreground, the role style colors
+// and the finder frame and ball colors; the light colors are the
+// background and the logo plate. Transparent colors are composited the
+// way they appear when printed: the background over white paper, the
+// plate over the background, and the dark colors over the light ones.
+func (r *QRRenderer) WorstContrastRatio() float64 {
+	worst := math.Inf(1)
+	for _, light := range r.lightColors() {
+		for _, dark := range r.darkColors() {
+			worst = min(worst, ContrastRatio(overNRGBA(light, dark), light))
+		}
+	}
+
+	return worst
+}
+
+// CheckContrast returns an error if the worst-case
+// contrast ratio is below MinContrastRatio.
+func (r *QRRenderer) CheckContrast() error {
+	if ratio := r.WorstContrastRatio(); ratio < MinContrastRatio {
+		return fmt.Errorf("contrast ratio %.2f:1 is below the minimum of %.2f:1", ratio, MinContrastRatio)
+	}
+	return nil
+}
+
+// paintColors returns the stop colors of
+// the gradient, or the solid color.
+func paintColors(c color.NRGBA, p *gradientPaint) []color.NRGBA {
+	if p == nil || len(p.colors) == 0 {
+		return []color.NRGBA{c}
+	}
+	return p.colors
+}
+
+// darkColors returns the colors drawing the dark modules.
+func (r *QRRenderer) darkColors() []color.NRGBA {
+	colors := slices.Clone(paintColors(r.foregroundColor, r.foregroundPaint))
+	for _, style := range r.roleStyles {
+		if style.hasColor {
+			colors = append(colors, style.color)
+		}
+	}
+	if style := r.finderStyle; style != nil {
+		for _, c := range []color.Color{style.FrameColor, style.BallColor} {
+			if c != nil {
+				colors = append(colors, color.NRGBAModel.Convert(c).(color.NRGBA))
+			}
+		}
+	}
+
+	return colors
+}
+
+// lightColors returns the opaque colors under the dark
+// modules, printed on white paper.
+func (r *QRRenderer) lightColors() []color.NRGBA {
+	var colors []color.NRGBA
+	for _, bg := range paintColors(r.backgroundColor, r.backgroundPaint) {
+		colors = append(colors, overNRGBA(color.NRGBA{255, 255, 255, 255}, bg))
+	}
+	if r.logo != nil && r.logoOptions.Plate && r.logoOptions.PlateColor != nil {
+		plate := color.NRGBAModel.Convert(r.logoOptions.PlateColor).(color.NRGBA)
+		for _, paper := range colors {
+			colors = append(colors, overNRGBA(paper, plate))
+		}
+	}
+
+	return colors
+}
+
+// relativeLuminance returns the WCAG relative luminance of the sRGB color.
+func relativeLuminance(c color.Color) float64 {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= .04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+.055)/1.055, 2.4)
+	}
+
+	return .2126*linear(nrgba.R) + .7152*linear(nrgba.G) + .0722*linear(nrgba.B)
+}
+
+// overNRGBA composites src over the opaque color dst.
+func overNRGBA(dst, src color.NRGBA) color.NRGBA {
+	a := float64(src.A) / 255
+	blend := func(d, s uint8) uint8 {
+		return uint8(float64(d)*(1-a) + float64(s)*a + .5)
+	}
+
+	return color.NRGBA{
+		blend(dst.R, src.R),
+		blend(dst.G, src.G),
+		blend(dst.B, src.B),
+		255,
+	}
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func TestContrastRatio(t *testing.T) {
+	if got := ContrastRatio(color.Black, color.White); math.Abs(got-21) > 1e-9 {
+		t.Errorf("got black on white %.4f:1, want 21:1", got)
+	}
+	if got := ContrastRatio(color.White, color.Black); math.Abs(got-21) > 1e-9 {
+		t.Errorf("got white on black %.4f:1, want 21:1", got)
+	}
+	gray := color.NRGBA{128, 128, 128, 255}
+	if got := ContrastRatio(gray, gray); got != 1 {
+		t.Errorf("got gray on gray %.4f:1, want 1:1", got)
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	lightGray := color.NRGBA{220, 220, 220, 255}
+	logo := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	plate := DefaultLogoOptions()
+	plate.Plate = true
+	plate.PlateColor = color.NRGBA{40, 40, 40, 255}
+
+	for _, tc := range []struct {
+		name string
+		r    *QRRenderer
+		ok   bool
+	}{
+		{"default", NewRenderer(), true},
+		{"light foreground", NewRenderer().WithForegroundColor(lightGray), false},
+		{"translucent foreground", NewRenderer().WithForegroundColor(color.NRGBA{0, 0, 0, 40}), false},
+		{"dark background", NewRenderer().WithBackgroundColor(color.NRGBA{30, 30, 30, 255}), false},
+		{"transparent background", NewRenderer().WithBackgroundColor(color.NRGBA{}), true},
+		{"gradient stop", NewRenderer().WithForegroundGradient(NewLinearGradient(0,
+			GradientStop{0, color.Black},
+			GradientStop{1, lightGray},
+		)), false},
+		{"role color", NewRenderer().WithRoleStyle(qrconst.FPAlignment, ModuleStyle{
+			Shape: qrconst.Square,
+			Color: lightGray,
+		}), false},
+		{"finder ball color", NewRenderer().WithFinderStyle(FinderStyle{BallColor: lightGray}), false},
+		{"finder frame color", NewRenderer().WithFinderStyle(FinderStyle{FrameColor: color.Black}), true},
+		{"logo plate color", NewRenderer().WithLogo(logo, plate), false},
+	} {
+		err := tc.r.CheckContrast()
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%s: got contrast ratio %.2f:1 error %v, want ok %v", tc.name, tc.r.WorstContrastRatio(), err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// DebugOptions controls the overlays drawn by the debug renderer.
+type DebugOptions struct {
+	// ShowMask marks the message modules inverted by the mask pattern.
+	ShowMask bool
+	// ShowCodewords outlines every codeword and prints its index.
+	ShowCodewords bool
+	// ShowLegend appends a legend of the module roles below the symbol.
+	ShowLegend bool
+}
+
+type debugRole int
+
+const (
+	debugUnoccupied debugRole = iota
+	debugFinder
+	debugSeparator
+	debugAlignment
+	debugTiming
+	debugDarkModule
+	debugFormatInfo
+	debugVersionInfo
+	debugDataCodeword
+	debugECCodeword
+	debugRemainderBit
+)
+
+var debugStyles = []struct {
+	label string
+	dark  color.RGBA
+	light color.RGBA
+}{
+	debugUnoccupied:   {"Unoccupied", color.RGBA{158, 158, 158, 255}, color.RGBA{245, 245, 245, 255}},
+	debugFinder:       {"Finder pattern", color.RGBA{21, 101, 192, 255}, color.RGBA{187, 222, 251, 255}},
+	debugSeparator:    {"Separator", color.RGBA{96, 125, 139, 255}, color.RGBA{207, 216, 220, 255}},
+	debugAlignment:    {"Alignment pattern", color.RGBA{46, 125, 50, 255}, color.RGBA{200, 230, 201, 255}},
+	debugTiming:       {"Timing pattern", color.RGBA{239, 108, 0, 255}, color.RGBA{255, 224, 178, 255}},
+	debugDarkModule:   {"Dark module", color.RGBA{198, 40, 40, 255}, color.RGBA{255, 205, 210, 255}},
+	debugFormatInfo:   {"Format information", color.RGBA{106, 27, 154, 255}, color.RGBA{225, 190, 231, 255}},
+	debugVersionInfo:  {"Version information", color.RGBA{0, 131, 143, 255}, color.RGBA{178, 235, 242, 255}},
+	debugDataCodeword: {"Data codeword", color.RGBA{33, 33, 33, 255}, color.RGBA{255, 255, 255, 255}},
+	debugECCodeword:   {"Error correction codeword", color.RGBA{93, 64, 55, 255}, color.RGBA{239, 235, 233, 255}},
+	debugRemainderBit: {"Remainder bit", color.RGBA{117, 117, 117, 255}, color.RGBA{224, 224, 224, 255}},
+}
+
+var (
+	debugMaskColor     = color.RGBA{216, 27, 96, 255}
+	debugCodewordColor = color.RGBA{255, 87, 34, 255}
+)
+
+const debugLegendRowHeight = 20
+
+// debugLayout holds the role and codeword index of every module.
+// Codeword indices follow the interleaved message order, and are
+// -1 for modules that are not part of a codeword. Modules holding
+// the most significant bit of a codeword are marked as starts.
+type debugLayout struct {
+	roles     [][]debugRole
+	codewords [][]int
+	starts    [][]bool
+	present   []debugRole
+}
+
+func newDebugLayout(qr qrcode.QRCode) debugLayout {
+	ecBlockInfo := tables.ECBlockInfos[qr.ECLevel][qr.Version-1]
+	dataCodewords := ecBlockInfo.Group1Blocks*ecBlockInfo.Group1DataCodewordsPerBlock +
+		ecBlockInfo.Group2Blocks*ecBlockInfo.Group2DataCodewordsPerBlock
+	totalCodewords := dataCodewords +
+		(ecBlockInfo.Group1Blocks+ecBlockInfo.Group2Blocks)*ecBlockInfo.ECCodewordsPerBlock
+
+	roles := make([][]debugRole, qr.Size)
+	codewords := make([][]int, qr.Size)
+	starts := make([][]bool, qr.Size)
+	for y := range qr.Size {
+		roles[y] = make([]debugRole, qr.Size)
+		codewords[y] = make([]int, qr.Size)
+		starts[y] = make([]bool, qr.Size)
+		for x := range qr.Size {
+			codewords[y][x] = -1
+
+			switch qr.Patterns[y][x] {
+			case qrconst.FPFinder:
+				roles[y][x] = debugFinder
+			case qrconst.FPSeparator:
+				roles[y][x] = debugSeparator
+			case qrconst.FPAlignment:
+				roles[y][x] = debugAlignment
+			case qrconst.FPTiming:
+				roles[y][x] = debugTiming
+			case qrconst.FPDarkModule:
+				roles[y][x] = debugDarkModule
+			case qrconst.FPFormatInfo:
+				roles[y][x] = debugFormatInfo
+			case qrconst.FPVersionInfo:
+				roles[y][x] = debugVersionInfo
+			default:
+				roles[y][x] = debugUnoccupied
+			}
+		}
+	}
+
+	for idx, pos := range matrix.MessageBitPositions(qr.Patterns) {
+		if !qr.Patterns[pos[0]][pos[1]].IsMessage() {
+			continue
+		}
+
+		codeword := idx / 8
+		starts[pos[0]][pos[1]] = idx%8 == 0 && codeword < totalCodewords
+		switch {
+		case codeword < dataCodewords:
+			roles[pos[0]][pos[1]] = debugDataCodeword
+			codewords[pos[0]][pos[1]] = codeword
+		case codeword < totalCodewords:
+			roles[pos[0]][pos[1]] = debugECCodeword
+			codewords[pos[0]][pos[1]] = codeword
+		default:
+			roles[pos[0]][pos[1]] = debugRemainderBit
+		}
+	}
+
+	seen := make([]bool, len(debugStyles))
+	for _, row := range roles {
+		for _, role := range row {
+			seen[role] = true
+		}
+	}
+	var present []debugRole
+	for role, ok := range seen {
+		if ok {
+			present = append(present, debugRole(role))
+		}
+	}
+
+	return debugLayout{
+		roles:     roles,
+		codewords: codewords,
+		starts:    starts,
+		present:   present,
+	}
+}
+
+// RenderDebugImage renders the QR Code with every module colored by
+// its function pattern role, optionally overlaid with the mask pattern,
+// the codeword boundaries and a legend.
+func (r *QRRenderer) RenderDebugImage(
+	qr qrcode.QRCode,
+	opts DebugOptions,
+) image.Image {
+	scale, margin, imgSize := r.layout(qr)
+	layout := newDebugLayout(qr)
+
+	imgHeight := imgSize
+	if opts.ShowLegend {
+		imgHeight += len(layout.present)*debugLegendRowHeight + margin/2
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, imgSize, imgHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// Modules colored by role
+	for y := range qr.Size {
+		for x := range qr.Size {
+			style := debugStyles[layout.roles[y][x]]
+			c := style.light
+			if qr.Modules[y][x] {
+				c = style.dark
+			}
+			draw.Draw(img, moduleRect(x, y, scale, margin), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+
+	// Mask pattern
+	if opts.ShowMask {
+		maskPattern := tables.MaskPatterns[qr.MaskNum]
+		dot := max(2, scale/4)
+		for y := range qr.Size {
+			for x := range qr.Size {
+				if qr.Patterns[y][x].IsMessage() && maskPattern(y, x) {
+					cx := margin + x*scale + scale/2
+					cy := margin + y*scale + scale/2
+					draw.Draw(
+						img,
+						image.Rect(cx-dot/2, cy-dot/2, cx-dot/2+dot, cy-dot/2+dot),
+						image.NewUniform(debugMaskColor),
+						image.Point{},
+						draw.Src,
+					)
+				}
+			}
+		}
+	}
+
+	// Codeword boundaries and indices
+	if opts.ShowCodewords {
+		line := max(1, scale/10)
+		boundary := image.NewUniform(debugCodewordColor)
+		for y := range qr.Size {
+			for x := range qr.Size {
+				codeword := layout.codewords[y][x]
+				if codeword < 0 {
+					continue
+				}
+
+				rect := moduleRect(x, y, scale, margin)
+				if x == 0 || layout.codewords[y][x-1] != codeword {
+					draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+line, rect.Max.Y), boundary, image.Point{}, draw.Src)
+				}
+				if x == qr.Size-1 || layout.codewords[y][x+1] != codeword {
+					draw.Draw(img, image.Rect(rect.Max.X-line, rect.Min.Y, rect.Max.X, rect.Max.Y), boundary, image.Point{}, draw.Src)
+				}
+				if y == 0 || layout.codewords[y-1][x] != codeword {
+					draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+line), boundary, image.Point{}, draw.Src)
+				}
+				if y == qr.Size-1 || layout.codewords[y+1][x] != codeword {
+					draw.Draw(img, image.Rect(rect.Min.X, rect.Max.Y-line, rect.Max.X, rect.Max.Y), boundary, image.Point{}, draw.Src)
+				}
+
+				if scale >= 14 && layout.starts[y][x] {
+					drawText(img, rect.Min.X+line+1, rect.Max.Y-line-2, fmt.Sprint(codeword), debugCodewordColor)
+				}
+			}
+		}
+	}
+
+	// Legend
+	if opts.ShowLegend {
+		top := imgSize - margin/2
+		for i, role := range layout.present {
+			style := debugStyles[role]
+			y := top + i*debugLegendRowHeight
+			draw.Draw(img, image.Rect(margin, y+3, margin+14, y+17), image.NewUniform(style.dark), image.Point{}, draw.Src)
+			draw.Draw(img, image.Rect(margin+14, y+3, margin+28, y+17), image.NewUniform(style.light), image.Point{}, draw.Src)
+			drawText(img, margin+36, y+14, style.label, color.Black)
+		}
+	}
+
+	return img
+}
+
+// RenderDebugToWriter encodes the debug rendering of the QR Code
+// in the given raster format.
+func (r *QRRenderer) RenderDebugToWriter(
+	qr qrcode.QRCode,
+	w io.Writer,
+	format qrconst.RenderFormat,
+	opts DebugOptions,
+) error {
+	img := r.RenderDebugImage(qr, opts)
+
+	switch format {
+	case qrconst.RenderPNG:
+		return png.Encode(w, img)
+	case qrconst.RenderJPEG:
+		return jpeg.Encode(w, img, nil)
+	default:
+		return fmt.Errorf("unsupported render format")
+	}
+}
+
+// RenderDebugSVG writes the debug rendering of the QR Code as SVG,
+// one rect per module so that each role can be inspected in a browser.
+// It uses the same pixel layout as RenderDebugImage.
+func (r *QRRenderer) RenderDebugSVG(
+	qr qrcode.QRCode,
+	w io.Writer,
+	opts DebugOptions,
+) error {
+	scale, margin, imgSize := r.layout(qr)
+	layout := newDebugLayout(qr)
+
+	width := imgSize
+	height := imgSize
+	if opts.ShowLegend {
+		height += len(layout.present)*debugLegendRowHeight + margin/2
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(
+		bw, `<svg
+	xmlns="http://www.w3.org/2000/svg"
+	viewBox="0 0 %d %d"
+	width="%d" height="%d"
+	font-family="monospace" font-size="12"
+>
+	<rect width="100%%" height="100%%" fill="#fff"/>
+`,
+		width, height,
+		width, height,
+	)
+
+	// Modules colored by role
+	fmt.Fprint(bw, "\t<g shape-rendering=\"crispEdges\">\n")
+	for y := range qr.Size {
+		for x := range qr.Size {
+			role := layout.roles[y][x]
+			style := debugStyles[role]
+			c := style.light
+			if qr.Modules[y][x] {
+				c = style.dark
+			}
+			fmt.Fprintf(
+				bw, "\t\t<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"><title>(%d, %d) %s</title></rect>\n",
+				margin+x*scale, margin+y*scale, scale, scale,
+				hexColor(c),
+				x, y, style.label,
+			)
+		}
+	}
+	fmt.Fprint(bw, "\t</g>\n")
+
+	// Mask pattern
+	if opts.ShowMask {
+		maskPattern := tables.MaskPatterns[qr.MaskNum]
+		fmt.Fprintf(bw, "\t<g fill=\"%s\">\n", hexColor(debugMaskColor))
+		for y := range qr.Size {
+			for x := range qr.Size {
+				if qr.Patterns[y][x].IsMessage() && maskPattern(y, x) {
+					fmt.Fprintf(
+						bw, "\t\t<circle cx=\"%d\" cy=\"%d\" r=\"%d\"/>\n",
+						margin+x*scale+scale/2, margin+y*scale+scale/2, scale/6,
+					)
+				}
+			}
+		}
+		fmt.Fprint(bw, "\t</g>\n")
+	}
+
+	// Codeword boundaries and indices
+	if opts.ShowCodewords {
+		fmt.Fprintf(bw, "\t<path fill=\"none\" stroke=\"%s\" stroke-width=\"2\" d=\"", hexColor(debugCodewordColor))
+		for y := range qr.Size {
+			for x := range qr.Size {
+				codeword := layout.codewords[y][x]
+				if codeword < 0 {
+					continue
+				}
+
+				px, py := margin+x*scale, margin+y*scale
+				if x == 0 || layout.codewords[y][x-1] != codeword {
+					fmt.Fprintf(bw, "M%d %dv%d", px, py, scale)
+				}
+				if x == qr.Size-1 || layout.codewords[y][x+1] != codeword {
+					fmt.Fprintf(bw, "M%d %dv%d", px+scale, py, scale)
+				}
+				if y == 0 || layout.codewords[y-1][x] != codeword {
+					fmt.Fprintf(bw, "M%d %dh%d", px, py, scale)
+				}
+				if y == qr.Size-1 || layout.codewords[y+1][x] != codeword {
+					fmt.Fprintf(bw, "M%d %dh%d", px, py+scale, scale)
+				}
+			}
+		}
+		fmt.Fprint(bw, "\"/>\n")
+
+		fmt.Fprintf(bw, "\t<g fill=\"%s\" font-size=\"9\">\n", hexColor(debugCodewordColor))
+		for y := range qr.Size {
+			for x := range qr.Size {
+				if layout.starts[y][x] {
+					fmt.Fprintf(
+						bw, "\t\t<text x=\"%d\" y=\"%d\">%d</text>\n",
+						margin+x*scale+3, margin+(y+1)*scale-4, layout.codewords[y][x],
+					)
+				}
+			}
+		}
+		fmt.Fprint(bw, "\t</g>\n")
+	}
+
+	// Legend
+	if opts.ShowLegend {
+		top := imgSize - margin/2
+		for i, role := range layout.present {
+			style := debugStyles[role]
+			y := top + i*debugLegendRowHeight
+			fmt.Fprintf(
+				bw, "\t<rect x=\"%d\" y=\"%d\" width=\"14\" height=\"14\" fill=\"%s\"/>\n"+
+					"\t<rect x=\"%d\" y=\"%d\" width=\"14\" height=\"14\" fill=\"%s\"/>\n"+
+					"\t<text x=\"%d\" y=\"%d\">%s</text>\n",
+				margin, y+3, hexColor(style.dark),
+				margin+14, y+3, hexColor(style.light),
+				margin+36, y+14, style.label,
+			)
+		}
+	}
+
+	fmt.Fprint(bw, "</svg>\n")
+
+	return bw.Flush()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// drawText draws s with its baseline starting at (x, y)
+// using the built-in 7x13 bitmap font.
+func drawText(img draw.Image, x, y int, s string, c color.Color) {
+	face := basicfont.Face7x13
+	src := image.NewUniform(c)
+	for _, r := range s {
+		dr, mask, maskp, advance, ok := face.Glyph(fixed.P(x, y), r)
+		if ok {
+			draw.DrawMask(img, dr, src, image.Point{}, mask, maskp, draw.Over)
+		}
+		x += advance.Round()
+	}
+}
@@ -0,0 +1,154 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// EncoderOptions controls the raster encoders.
+type EncoderOptions struct {
+	// JPEGQuality ranges from 1 to 100, higher is better. Zero
+	// uses the default quality of the encoder.
+	JPEGQuality int
+	// PNGCompression is the compression level of the PNG output,
+	// including the paletted and 1-bit PNG output.
+	PNGCompression png.CompressionLevel
+}
+
+// WithEncoderOptions sets the quality and the compression
+// of the raster output.
+func (r *QRRenderer) WithEncoderOptions(
+	opts EncoderOptions,
+) *QRRenderer {
+	r.encoderOptions = opts
+	return r
+}
+
+func (r *QRRenderer) encodePNG(w io.Writer, img image.Image) error {
+	enc := png.Encoder{CompressionLevel: r.encoderOptions.PNGCompression}
+	return enc.Encode(w, img)
+}
+
+// exactPalette returns img as a paletted image when it has at most 256
+// distinct colors, which is the case of unblurred output without
+// gradients or logos, e.g. two colors and their antialiased blends.
+func exactPalette(img image.Image) (*image.Paletted, bool) {
+	b := img.Bounds()
+	index := make(map[color.NRGBA]uint8)
+	var pal color.Palette
+	out := image.NewPaletted(b, nil)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				c = color.NRGBA{}
+			}
+			i, ok := index[c]
+			if !ok {
+				if len(pal) == 256 {
+					return nil, false
+				}
+				i = uint8(len(pal))
+				index[c] = i
+				pal = append(pal, c)
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+
+	out.Palette = pal
+	return out, true
+}
+
+// encodePalettedPNG writes img as a paletted PNG, whose bit depth the
+// encoder lowers to fit the palette, or as a plain PNG when img has
+// more than 256 colors.
+func (r *QRRenderer) encodePalettedPNG(w io.Writer, img image.Image) error {
+	if p, ok := exactPalette(img); ok {
+		return r.encodePNG(w, p)
+	}
+	return r.encodePNG(w, img)
+}
+
+// encodeMonoPNG writes img as a 1-bit PNG, with every pixel set to
+// the nearer of the background and the foreground colors, or of white
+// and black when either is a gradient.
+func (r *QRRenderer) encodeMonoPNG(w io.Writer, img image.Image) error {
+	light, dark := r.backgroundColor, r.foregroundColor
+	if r.backgroundPaint != nil || r.foregroundPaint != nil {
+		light, dark = color.NRGBA{255, 255, 255, 255}, color.NRGBA{0, 0, 0, 255}
+	}
+
+	pal := color.Palette{light, dark}
+	b := img.Bounds()
+	out := image.NewPaletted(b, pal)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			out.SetColorIndex(x, y, uint8(pal.Index(img.At(x, y))))
+		}
+	}
+
+	return r.encodePNG(w, out)
+}
+
+// encodeGIF writes img as a GIF. GIF transparency is all or nothing, so
+// pixels at least half opaque are flattened over white, and the others
+// left transparent. Images with more than 256 colors are dithered to
+// the Plan 9 palette.
+func encodeGIF(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	binary := image.NewNRGBA(b)
+	transparent := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				transparent = true
+				continue
+			}
+
+			a := float64(c.A) / 255
+			blend := func(v uint8) uint8 {
+				return clampUint8(float64(v)*a + 255*(1-a))
+			}
+			binary.SetNRGBA(x, y, color.NRGBA{blend(c.R), blend(c.G), blend(c.B), 255})
+		}
+	}
+
+	p, ok := exactPalette(binary)
+	if !ok {
+		pal := palette.Plan9
+		if transparent {
+			// Give up the last Plan 9 color for transparency
+			pal = append(pal[:255:255], color.NRGBA{})
+		}
+		p = image.NewPaletted(b, pal)
+		draw.FloydSteinberg.Draw(p, b, binary, b.Min)
+	}
+
+	return gif.Encode(w, p, nil)
+}
+
+// encodeBMP writes img as a BMP flattened over white, as readers ignore
+// the alpha channel of BMP files, with a palette when img has at most
+// 256 colors.
+func encodeBMP(w io.Writer, img image.Image) error {
+	flat := flatten(img, color.White)
+	if p, ok := exactPalette(flat); ok {
+		return bmp.Encode(w, p)
+	}
+	return bmp.Encode(w, flat)
+}
+
+// encodeTIFF writes img as a losslessly compressed TIFF.
+func encodeTIFF(w io.Writer, img image.Image) error {
+	return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate, Predictor: true})
+}
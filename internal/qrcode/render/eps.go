@@ -0,0 +1,518 @@
+package render
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// EPSOptions controls the EPS output, with lengths in millimeters.
+type EPSOptions struct {
+	// Size is the width of the symbol, quiet zone excluded. A zero
+	// size draws the modules as large as the raster output printed
+	// at its resolution.
+	Size float64
+	// CMYK writes the colors and images as CMYK, converted from RGB.
+	// CMYK and spot colors are written as such either way.
+	CMYK bool
+	// Title is the title of the document.
+	Title string
+}
+
+// WithEPSOptions sets the size and the colors of the EPS output.
+func (r *QRRenderer) WithEPSOptions(
+	opts EPSOptions,
+) *QRRenderer {
+	r.epsOptions = opts
+	return r
+}
+
+// RenderEPS writes the QR Code as Encapsulated PostScript, with every
+// module shape drawn as vector paths, and the bounding box enclosing the
+// symbol with its quiet zone. PostScript has no transparency: colors are
+// painted opaque, dimmed logo areas are left undimmed, and images are
+// flattened over the plate or the background. Linear and radial
+// gradients need a PostScript level 3 device.
+func (r *QRRenderer) RenderEPS(qr qrcode.QRCode, w io.Writer) error {
+	opts := r.epsOptions
+	width := r.printWidth(qr, opts.Size) * pointsPerMM
+
+	if r.logo != nil && r.logoOptions.Mode == qrconst.LogoClear {
+		qr = r.clearLogoArea(qr)
+	}
+	if r.finderStyle != nil {
+		qr = clearFinders(qr)
+	}
+
+	d := &epsDocument{
+		cmyk:    opts.CMYK,
+		level:   2,
+		symbols: make(map[symbolUse]string),
+		spots:   make(map[string]color.CMYK),
+	}
+
+	// Draw in modules, with the y axis pointing down like in SVG,
+	// and the quiet zone starting at (0, 0)
+	quietZone := r.quietZone
+	origin, size := float64(quietZone), float64(qr.Size)
+	modules := float64(qr.Size + 2*quietZone)
+	c := d.content()
+	c.op("[%s 0 0 %s 0 %s] concat", pdf.Num(width/modules), pdf.Num(-width/modules), pdf.Num(width))
+
+	foreground := c.colorOp(r.foregroundInk)
+	if r.foregroundPaint != nil {
+		foreground = d.pattern(c, r.foregroundPaint, origin, size, modules)
+	}
+	background := ""
+	switch {
+	case r.backgroundPaint != nil:
+		background = d.pattern(c, r.backgroundPaint, origin, size, modules)
+	case r.backgroundColor.A > 0:
+		background = c.colorOp(r.backgroundInk)
+	}
+	// backdrop returns the color below the point (x, y), which the
+	// images are flattened over
+	backdrop := func(x, y float64) color.NRGBA {
+		switch {
+		case r.backgroundPaint != nil:
+			return r.backgroundPaint.at((x-origin)/size, (y-origin)/size)
+		case r.backgroundColor.A > 0:
+			return r.backgroundColor
+		}
+		return color.NRGBA{255, 255, 255, 255}
+	}
+
+	if background != "" {
+		c.op("%s", background)
+		c.op("0 0 %s %s re fill", pdf.Num(modules), pdf.Num(modules))
+	}
+
+	for _, group := range r.moduleGroups(qr, false, true) {
+		if len(group.uses) == 0 && group.tiles == nil {
+			continue
+		}
+
+		paint := foreground
+		if group.style.hasColor {
+			paint = c.colorOp(group.style.ink)
+		}
+		c.op("%s", paint)
+		for _, use := range group.uses {
+			c.op("%d %d %s", quietZone+use.x, quietZone+use.y, d.symbol(use.def, use.name))
+		}
+		if group.tiles != nil {
+			c.path(vector.MustParsePath(squareOutline(group.tiles, quietZone, quietZone)))
+			c.op("fill")
+		}
+	}
+
+	if r.finderStyle != nil {
+		r.drawEyesEPS(c, qr, origin, foreground, backdrop)
+	}
+	if r.logo != nil {
+		r.drawLogoEPS(c, qr, origin, background, backdrop)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(bw, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(width)))
+	fmt.Fprintf(bw, "%%%%HiResBoundingBox: 0 0 %s %s\n", pdf.Num(width), pdf.Num(width))
+	if opts.Title != "" {
+		fmt.Fprintf(bw, "%%%%Title: %s\n", pdf.Text(opts.Title))
+	}
+	fmt.Fprintf(bw, "%%%%Creator: qrgen\n%%%%LanguageLevel: %d\n%%%%DocumentData: Clean7Bit\n", d.level)
+	if len(d.spots) > 0 {
+		names := slices.Sorted(maps.Keys(d.spots))
+		var custom []string
+		for _, name := range names {
+			custom = append(custom, psString(name))
+		}
+		fmt.Fprintf(bw, "%%%%DocumentCustomColors: %s\n", strings.Join(custom, " "))
+		for i, name := range names {
+			prefix := "%%+"
+			if i == 0 {
+				prefix = "%%CMYKCustomColor:"
+			}
+			fmt.Fprintf(bw, "%s %s %s\n", prefix, cmykComponents(d.spots[name]), custom[i])
+		}
+	}
+	bw.WriteString("%%EndComments\n")
+
+	// The procedures live in a dictionary of their own,
+	// to leave the including document untouched
+	bw.WriteString(`%%BeginProlog
+/qrgen 64 dict def
+qrgen begin
+/m /moveto load def
+/l /lineto load def
+/c /curveto load def
+/h /closepath load def
+/re { 4 2 roll moveto 1 index 0 rlineto 0 exch rlineto neg 0 rlineto closepath } bind def
+`)
+	bw.Write(d.prolog.Bytes())
+	bw.WriteString("end\n%%EndProlog\nqrgen begin\ngsave\n")
+	bw.Write(c.Bytes())
+	bw.WriteString("grestore\nend\nshowpage\n%%Trailer\n%%EOF\n")
+
+	return bw.Flush()
+}
+
+// epsDocument holds the procedures and the patterns of an EPS file.
+type epsDocument struct {
+	cmyk bool
+	// level is the PostScript language level the content needs
+	level  int
+	prolog epsContent
+	// symbols holds the names of the procedures drawing the symbols at
+	// the point on the stack, keyed by their use at (0, 0)
+	symbols  map[symbolUse]string
+	patterns int
+	// spots holds the CMYK colors of the spot colors used, by name
+	spots map[string]color.CMYK
+}
+
+func (d *epsDocument) content() *epsContent {
+	return &epsContent{cmyk: d.cmyk, spots: d.spots}
+}
+
+// symbol returns the name of the procedure drawing a symbol of the
+// shape in the current color. PostScript has no masks, so every layer
+// is clipped by the cut layers above it instead, one cut polygon at a
+// time.
+func (d *epsDocument) symbol(def *shapeDef, name string) string {
+	key := symbolUse{def: def, name: name}
+	if proc, ok := d.symbols[key]; ok {
+		return proc
+	}
+
+	layers := def.symbols[name]
+	x0, y0, x1, y1 := symbolBounds(layers)
+	bounds := fmt.Sprintf("%s %s %s %s re", pdf.Num(x0), pdf.Num(y0), pdf.Num(x1-x0), pdf.Num(y1-y0))
+
+	c := d.content()
+	for i, layer := range layers {
+		if layer.Cut {
+			continue
+		}
+
+		var cuts [][]vector.Point
+		for _, above := range layers[i+1:] {
+			if above.Cut {
+				fill, stroke := layerPolygons(above, 1, shapeTolerance/100)
+				cuts = append(append(cuts, fill...), stroke...)
+			}
+		}
+		if len(cuts) == 0 {
+			c.layer(layer)
+			continue
+		}
+
+		// The cut polygons lie inside the bounds,
+		// so the even-odd rule cuts them out
+		c.op("gsave")
+		for _, polygon := range cuts {
+			c.op("%s", bounds)
+			c.polygon(polygon)
+			c.op("eoclip newpath")
+		}
+		c.layer(layer)
+		c.op("grestore")
+	}
+
+	proc := fmt.Sprintf("S%d", len(d.symbols))
+	d.prolog.op("/%s {\ngsave translate", proc)
+	d.prolog.Write(c.Bytes())
+	d.prolog.op("grestore\n} bind def")
+	d.symbols[key] = proc
+	return proc
+}
+
+// pattern defines the gradient as a pattern, and returns the operation
+// painting with it. The symbol spans size user units from (origin,
+// origin), and the page area to cover spans extent user units from
+// (0, 0). Conic gradients are painted as solid wedges, like in SVG.
+func (d *epsDocument) pattern(c *epsContent, p *gradientPaint, origin, size, extent float64) string {
+	if len(p.colors) == 0 {
+		return c.colorOp(ink{})
+	}
+	if p.Type == qrconst.RadialGradient && p.Radius <= 0 {
+		return c.colorOp(p.inkAt(1))
+	}
+
+	name := fmt.Sprintf("P%d", d.patterns)
+	d.patterns++
+	// Gradients with print colors are interpolated in CMYK
+	colorSpace, components := "/DeviceRGB", func(i ink) string {
+		return pdfRGB(i.rgb)
+	}
+	if d.cmyk || p.cmyk {
+		colorSpace, components = "/DeviceCMYK", func(i ink) string {
+			return cmykComponents(i.process())
+		}
+	}
+
+	// Patterns are laid out in the user space current when they are made
+	if p.Type == qrconst.ConicGradient {
+		c.op(
+			"/%s << /PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 %s %s] /XStep %s /YStep %s /PaintProc { pop",
+			name, pdf.Num(extent), pdf.Num(extent), pdf.Num(extent), pdf.Num(extent),
+		)
+		for _, wedge := range gradientWedges(p, origin, size, extent) {
+			c.op("%s %s fill", c.colorOp(wedge.ink), wedge.pdfPath())
+		}
+		c.op("} >> matrix makepattern def")
+		return name + " setpattern"
+	}
+
+	// Shading patterns are a level 3 feature
+	d.level = 3
+	shadingType, coords := shadingCoords(p, origin, size)
+	c.op("%s", wrapPS(fmt.Sprintf(
+		"/%s << /PatternType 2 /Shading << /ShadingType %d /ColorSpace %s /Coords [%s] /Function %s /Extend [true true] >> >> matrix makepattern def",
+		name, shadingType, colorSpace, coords, gradientFunction(p, components),
+	)))
+	return name + " setpattern"
+}
+
+// drawEyesEPS draws the frames and the balls of the finder patterns.
+// The symbol starts at (origin, origin) in user units, and the eye
+// images are flattened over the backdrop.
+func (r *QRRenderer) drawEyesEPS(c *epsContent, qr qrcode.QRCode, origin float64, foreground string, backdrop func(x, y float64) color.NRGBA) {
+	style := r.finderStyle
+	fill := func(path string, evenOdd bool, col color.Color) {
+		paint := foreground
+		if col != nil {
+			paint = c.colorOp(newInk(col))
+		}
+		c.op("%s", paint)
+		c.path(vector.MustParsePath(path))
+		if evenOdd {
+			c.op("eofill")
+		} else {
+			c.op("fill")
+		}
+	}
+
+	for _, eye := range finderEyes(qr) {
+		ox, oy := origin+float64(eye.x), origin+float64(eye.y)
+
+		if style.Frame == qrconst.EyeImage && style.FrameImage != nil {
+			c.image(style.FrameImage, ox, oy, 7, 7, backdrop)
+		} else {
+			fill(newEyeGeometry(style.Frame, false, eye.mirrorX, eye.mirrorY).path(ox, oy), true, style.FrameColor)
+		}
+
+		if style.Ball == qrconst.EyeImage && style.BallImage != nil {
+			c.image(style.BallImage, ox+2, oy+2, 3, 3, backdrop)
+		} else {
+			fill(newEyeGeometry(style.Ball, true, eye.mirrorX, eye.mirrorY).path(ox, oy), false, style.BallColor)
+		}
+	}
+}
+
+// drawLogoEPS draws the plate and the logo, clipped to the logo area
+// minus the function pattern modules. The symbol starts at (origin,
+// origin) in user units. The logo is flattened over the plate color,
+// or else over the backdrop.
+func (r *QRRenderer) drawLogoEPS(c *epsContent, qr qrcode.QRCode, origin float64, background string, backdrop func(x, y float64) color.NRGBA) {
+	opts := r.logoOptions
+	logo, area, protected := r.logoLayout(qr)
+
+	// The protected modules lie inside the area,
+	// so the even-odd rule cuts them out
+	c.op("gsave")
+	for _, b := range append([]logoBox{area}, protected...) {
+		c.op(
+			"%s %s %s %s re",
+			pdf.Num(origin+b.x0), pdf.Num(origin+b.y0), pdf.Num(b.x1-b.x0), pdf.Num(b.y1-b.y0),
+		)
+	}
+	c.op("eoclip newpath")
+
+	if opts.Plate {
+		paint := background
+		if opts.PlateColor != nil {
+			plate := newInk(opts.PlateColor)
+			paint = c.colorOp(plate)
+			backdrop = func(x, y float64) color.NRGBA {
+				return plate.rgb
+			}
+		}
+
+		if paint != "" {
+			radius := min(max(0, opts.PlateRadius), (area.x1-area.x0)/2, (area.y1-area.y0)/2)
+			plate := eyeRect{area.x0, area.y0, area.x1 - area.x0, area.y1 - area.y0, [4]float64{radius, radius, radius, radius}}
+			c.op("%s", paint)
+			c.path(vector.MustParsePath(plate.path(origin, origin)))
+			c.op("fill")
+		}
+	}
+	c.image(r.logo, origin+logo.x0, origin+logo.y0, logo.x1-logo.x0, logo.y1-logo.y0, backdrop)
+	c.op("grestore")
+}
+
+// epsContent is the PostScript code of an EPS file.
+type epsContent struct {
+	vectorContent
+	cmyk bool
+	// spots collects the spot colors painted, by name
+	spots map[string]color.CMYK
+}
+
+// colorOp returns the operation setting the color, ignoring its opacity.
+// Spot colors are painted in a separation color space, falling back to
+// their CMYK color on devices without the ink.
+func (c *epsContent) colorOp(i ink) string {
+	switch {
+	case i.kind == spotInk:
+		c.spots[i.name] = i.cmyk
+		// The tint transform multiplies the CMYK color by the tint
+		cmyk := strings.Fields(cmykComponents(i.cmyk))
+		return fmt.Sprintf(
+			"[/Separation %s cvn /DeviceCMYK {dup %s mul exch dup %s mul exch dup %s mul exch %s mul}] setcolorspace %s setcolor",
+			psString(i.name), cmyk[0], cmyk[1], cmyk[2], cmyk[3], pdf.Num(i.tint),
+		)
+	case i.kind == cmykInk || c.cmyk:
+		return cmykComponents(i.process()) + " setcmykcolor"
+	}
+	return pdfRGB(i.rgb) + " setrgbcolor"
+}
+
+// cmykComponents returns the components of the CMYK color.
+func cmykComponents(c color.CMYK) string {
+	parts := make([]string, 0, 4)
+	for _, v := range []uint8{c.C, c.M, c.Y, c.K} {
+		parts = append(parts, pdf.Num(float64(v)/255))
+	}
+	return strings.Join(parts, " ")
+}
+
+// psString formats s as a PostScript string, in hexadecimal
+// when it has bytes outside printable ASCII.
+func psString(s string) string {
+	for i := range len(s) {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return "<" + hex.EncodeToString([]byte(s)) + ">"
+		}
+	}
+	return pdf.Text(s)
+}
+
+// polygon writes the path of the closed polygon.
+func (c *epsContent) polygon(points []vector.Point) {
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		c.op("%s %s %s", pdf.Num(p.X), pdf.Num(p.Y), op)
+	}
+	c.op("h")
+}
+
+// layer draws the shape layer with the current color. The line cap
+// and join values of qrconst match the PostScript ones.
+func (c *epsContent) layer(layer tables.ShapeLayer) {
+	stroke := layer.StrokeWidth > 0
+	if stroke {
+		c.op(
+			"%s setlinewidth %d setlinecap %d setlinejoin 4 setmiterlimit",
+			pdf.Num(layer.StrokeWidth), layer.LineCap, layer.LineJoin,
+		)
+	}
+	c.path(vector.MustParsePath(layer.Path))
+
+	switch {
+	case layer.Fill && stroke:
+		c.op("gsave fill grestore stroke")
+	case layer.Fill:
+		c.op("fill")
+	case stroke:
+		c.op("stroke")
+	default:
+		c.op("newpath")
+	}
+}
+
+// image draws img scaled to the rectangle at (x, y) in user units,
+// flattened over the backdrop color at every pixel.
+func (c *epsContent) image(img image.Image, x, y, w, h float64, backdrop func(x, y float64) color.NRGBA) {
+	b := img.Bounds()
+	space, decode := "/DeviceRGB", "0 1 0 1 0 1"
+	if c.cmyk {
+		space, decode = "/DeviceCMYK", "0 1 0 1 0 1 0 1"
+	}
+
+	data := make([]byte, 0, 4*b.Dx()*b.Dy())
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			fg := color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			bg := backdrop(
+				x+(float64(px-b.Min.X)+.5)*w/float64(b.Dx()),
+				y+(float64(py-b.Min.Y)+.5)*h/float64(b.Dy()),
+			)
+			a := float64(fg.A) / 255
+			blend := func(s, d uint8) uint8 {
+				return clampUint8(float64(s)*a + float64(d)*(1-a))
+			}
+			r, g, bl := blend(fg.R, bg.R), blend(fg.G, bg.G), blend(fg.B, bg.B)
+
+			if c.cmyk {
+				cc, m, yy, k := color.RGBToCMYK(r, g, bl)
+				data = append(data, cc, m, yy, k)
+			} else {
+				data = append(data, r, g, bl)
+			}
+		}
+	}
+
+	// The image is read from the file right after the image operator,
+	// and its rows run down like the y axis
+	c.op("gsave")
+	c.op("%s %s translate %s %s scale %s setcolorspace", pdf.Num(x), pdf.Num(y), pdf.Num(w), pdf.Num(h), space)
+	c.op(
+		"<< /ImageType 1 /Width %d /Height %d /BitsPerComponent 8 /Decode [%s] /ImageMatrix [%d 0 0 %d 0 0] /DataSource currentfile /ASCIIHexDecode filter >> image",
+		b.Dx(), b.Dy(), decode, b.Dx(), b.Dy(),
+	)
+	for len(data) > 0 {
+		n := min(len(data), 40)
+		c.op("%s", hex.EncodeToString(data[:n]))
+		data = data[n:]
+	}
+	c.op(">")
+	c.op("grestore")
+}
+
+// wrapPS breaks PostScript code into lines of at most 255 characters,
+// the limit of the document structuring conventions, between tokens.
+func wrapPS(code string) string {
+	var b strings.Builder
+	line := 0
+	for i, token := range strings.Fields(code) {
+		if i > 0 {
+			if line+1+len(token) > 255 {
+				b.WriteByte('\n')
+				line = 0
+			} else {
+				b.WriteByte(' ')
+				line++
+			}
+		}
+		b.WriteString(token)
+		line += len(token)
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func epsQRCode(t *testing.T) qrcode.QRCode {
+	t.Helper()
+
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *qr
+}
+
+// renderEPS returns the header comments of the EPS output, without
+// their "%%" prefix, and the content following the prolog.
+func renderEPS(t *testing.T, r *QRRenderer, qr qrcode.QRCode) (comments []string, content string) {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := r.RenderEPS(qr, &b); err != nil {
+		t.Fatal(err)
+	}
+	header, rest, ok := strings.Cut(b.String(), "%%EndComments\n")
+	if !ok || !strings.HasPrefix(header, "%!PS-Adobe-3.0 EPSF-3.0\n") {
+		t.Fatalf("got EPS header %q", header)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(header, "\n"), "\n")[1:] {
+		comments = append(comments, strings.TrimPrefix(line, "%%"))
+	}
+	_, content, ok = strings.Cut(rest, "%%EndProlog\n")
+	if !ok {
+		t.Fatal("no prolog")
+	}
+	return comments, content
+}
+
+// epsComment returns the value of the header comment of the key.
+func epsComment(comments []string, key string) (string, bool) {
+	for _, comment := range comments {
+		if value, ok := strings.CutPrefix(comment, key+": "); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func TestEPSBoundingBox(t *testing.T) {
+	qr := epsQRCode(t)
+	for _, size := range []float64{20, 33.3, 100} {
+		for _, quietZone := range []int{0, 4} {
+			r := NewRenderer().WithQuietZone(quietZone).WithEPSOptions(EPSOptions{Size: size})
+			comments, _ := renderEPS(t, r, qr)
+
+			width := size * float64(qr.Size+2*quietZone) / float64(qr.Size) * pointsPerMM
+			box := strconv.Itoa(int(math.Ceil(width)))
+			if got, _ := epsComment(comments, "BoundingBox"); got != "0 0 "+box+" "+box {
+				t.Errorf("size %g quiet zone %d: got bounding box %q, want 0 0 %s %s", size, quietZone, got, box, box)
+			}
+			hiRes := pdf.Num(width)
+			if got, _ := epsComment(comments, "HiResBoundingBox"); got != "0 0 "+hiRes+" "+hiRes {
+				t.Errorf("size %g quiet zone %d: got high resolution bounding box %q, want 0 0 %s %s", size, quietZone, got, hiRes, hiRes)
+			}
+		}
+	}
+}
+
+func TestEPSLanguageLevel(t *testing.T) {
+	qr := epsQRCode(t)
+	stops := []GradientStop{
+		{0, color.NRGBA{200, 0, 0, 255}},
+		{1, color.NRGBA{0, 0, 200, 255}},
+	}
+	for _, tc := range []struct {
+		name  string
+		r     *QRRenderer
+		level string
+	}{
+		{"plain", NewRenderer(), "2"},
+		{"spot", NewRenderer().WithForegroundColor(SpotColor{Name: "Gold", Tint: 1}), "2"},
+		{"conic", NewRenderer().WithForegroundGradient(NewConicGradient(.5, .5, 0, stops...)), "2"},
+		{"degenerate radial", NewRenderer().WithForegroundGradient(NewRadialGradient(.5, .5, 0, stops...)), "2"},
+		{"linear", NewRenderer().WithForegroundGradient(NewLinearGradient(30, stops...)), "3"},
+		{"radial background", NewRenderer().WithBackgroundGradient(NewRadialGradient(.5, .5, .7, stops...)), "3"},
+	} {
+		comments, content := renderEPS(t, tc.r, qr)
+		if got, _ := epsComment(comments, "LanguageLevel"); got != tc.level {
+			t.Errorf("%s: got language level %q, want %s", tc.name, got, tc.level)
+		}
+		if shading := strings.Contains(content, "/PatternType 2"); shading != (tc.level == "3") {
+			t.Errorf("%s: got shading pattern %v at language level %s", tc.name, shading, tc.level)
+		}
+	}
+}
+
+func TestEPSCustomColors(t *testing.T) {
+	qr := epsQRCode(t)
+	gold := SpotColor{Name: "Gold", Tint: .5, CMYK: color.CMYK{0, 51, 255, 0}}
+	blue := SpotColor{Name: "PANTONE 286 C", Tint: 1, CMYK: color.CMYK{255, 170, 0, 5}}
+	r := NewRenderer().
+		WithForegroundColor(blue).
+		WithRoleStyle(qrconst.FPTiming, ModuleStyle{Color: gold})
+
+	comments, content := renderEPS(t, r, qr)
+	if got, _ := epsComment(comments, "DocumentCustomColors"); got != "(Gold) (PANTONE 286 C)" {
+		t.Errorf("got custom colors %q, want (Gold) (PANTONE 286 C)", got)
+	}
+	// The colors follow in the same order, the first
+	// on the comment line and the others continuing it
+	i := slices.IndexFunc(comments, func(comment string) bool {
+		return strings.HasPrefix(comment, "CMYKCustomColor: ")
+	})
+	if i < 0 || i+1 >= len(comments) {
+		t.Fatalf("got comments %q, want the CMYK custom colors", comments)
+	}
+	if got, want := comments[i], "CMYKCustomColor: 0 0.2 1 0 (Gold)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := comments[i+1], "+ 1 0.6667 0 0.0196 (PANTONE 286 C)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, s := range []string{"[/Separation (Gold) cvn", "[/Separation (PANTONE 286 C) cvn"} {
+		if !strings.Contains(content, s) {
+			t.Errorf("missing the separation %q", s)
+		}
+	}
+
+	comments, _ = renderEPS(t, NewRenderer(), qr)
+	for _, key := range []string{"DocumentCustomColors", "CMYKCustomColor"} {
+		if _, ok := epsComment(comments, key); ok {
+			t.Errorf("got %s comment without spot colors", key)
+		}
+	}
+}
+
+func TestEPSCMYK(t *testing.T) {
+	qr := epsQRCode(t)
+	r := NewRenderer().
+		WithBackgroundColor(color.NRGBA{255, 250, 220, 255}).
+		WithForegroundColor(color.NRGBA{200, 30, 30, 255})
+
+	for _, cmyk := range []bool{false, true} {
+		_, content := renderEPS(t, r.WithEPSOptions(EPSOptions{CMYK: cmyk}), qr)
+		rgb, process := strings.Count(content, " setrgbcolor"), strings.Count(content, " setcmykcolor")
+		if cmyk && (rgb > 0 || process == 0) || !cmyk && (rgb == 0 || process > 0) {
+			t.Errorf("CMYK %v: got %d setrgbcolor and %d setcmykcolor", cmyk, rgb, process)
+		}
+	}
+
+	// CMYK colors stay CMYK either way
+	_, content := renderEPS(t, NewRenderer().WithForegroundColor(color.CMYK{0, 0, 0, 255}), qr)
+	if !strings.Contains(content, "0 0 0 1 setcmykcolor") {
+		t.Error("the CMYK foreground is not painted as CMYK")
+	}
+}
@@ -0,0 +1,377 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+	xdraw "golang.org/x/image/draw"
+)
+
+// FinderStyle draws the finder patterns ("eyes") as whole shapes, a 7x7
+// frame around a 3x3 ball, instead of module by module. Asymmetric shapes
+// are mirrored for each corner of the symbol.
+type FinderStyle struct {
+	Frame qrconst.EyeShape
+	Ball  qrconst.EyeShape
+	// FrameColor and BallColor are the colors of the frame and the
+	// ball. A nil color uses the foreground color or gradient.
+	FrameColor color.Color
+	BallColor  color.Color
+	// FrameImage and BallImage are drawn, scaled to the 7x7 frame and
+	// the 3x3 ball, by the EyeImage shape. Without an image, EyeImage
+	// falls back to EyeSquare.
+	FrameImage image.Image
+	BallImage  image.Image
+}
+
+// WithFinderStyle draws the finder patterns with the given frame and
+// ball styles. It takes precedence over WithDefaultFinder.
+func (r *QRRenderer) WithFinderStyle(
+	style FinderStyle,
+) *QRRenderer {
+	r.finderStyle = &style
+	return r
+}
+
+// eyePrimitive is a filled shape, in modules
+// from the top-left corner of the finder pattern.
+type eyePrimitive interface {
+	// path returns the SVG path data of the
+	// shape, offset by (ox, oy) user units.
+	path(ox, oy float64) string
+}
+
+// eyeRect is a rectangle with rounded corners, with radii in the order
+// top-left, top-right, bottom-right, bottom-left.
+type eyeRect struct {
+	x, y, w, h float64
+	radii      [4]float64
+}
+
+func (e eyeRect) path(ox, oy float64) string {
+	x0, y0, x1, y1 := ox+e.x, oy+e.y, ox+e.x+e.w, oy+e.y+e.h
+	r := e.radii
+
+	var b strings.Builder
+	arc := func(radius, x, y float64) {
+		if radius > 0 {
+			fmt.Fprintf(&b, " A %s %s 0 0 1 %s %s", svgNum(radius), svgNum(radius), svgNum(x), svgNum(y))
+		}
+	}
+	fmt.Fprintf(&b, "M %s %s H %s", svgNum(x0+r[0]), svgNum(y0), svgNum(x1-r[1]))
+	arc(r[1], x1, y0+r[1])
+	fmt.Fprintf(&b, " V %s", svgNum(y1-r[2]))
+	arc(r[2], x1-r[2], y1)
+	fmt.Fprintf(&b, " H %s", svgNum(x0+r[3]))
+	arc(r[3], x0, y1-r[3])
+	fmt.Fprintf(&b, " V %s", svgNum(y0+r[0]))
+	arc(r[0], x0+r[0], y0)
+	b.WriteString(" Z")
+
+	return b.String()
+}
+
+// eyeSuperellipse is the shape |x|^n + |y|^n <= r^n
+// around (cx, cy), a square with bulging sides.
+type eyeSuperellipse struct {
+	cx, cy, r, n float64
+}
+
+func (e eyeSuperellipse) path(ox, oy float64) string {
+	const points = 72
+
+	var b strings.Builder
+	for i := range points {
+		t := 2 * math.Pi * float64(i) / points
+		cos, sin := math.Cos(t), math.Sin(t)
+		x := e.cx + e.r*math.Copysign(math.Pow(math.Abs(cos), 2/e.n), cos)
+		y := e.cy + e.r*math.Copysign(math.Pow(math.Abs(sin), 2/e.n), sin)
+
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&b, "%s %s %s ", cmd, svgNum(ox+x), svgNum(oy+y))
+	}
+	b.WriteString("Z")
+
+	return b.String()
+}
+
+// eyeGeometry is a shape made of non-overlapping filled
+// primitives, with holes cut out by the even-odd rule.
+type eyeGeometry struct {
+	fills []eyePrimitive
+	holes []eyePrimitive
+}
+
+func (g eyeGeometry) path(ox, oy float64) string {
+	paths := make([]string, 0, len(g.fills)+len(g.holes))
+	for _, p := range slices.Concat(g.fills, g.holes) {
+		paths = append(paths, p.path(ox, oy))
+	}
+	return strings.Join(paths, " ")
+}
+
+// coverage returns the coverage of the shape within bounds, drawn
+// with modules of scale pixels from the pixel offset (ox, oy).
+func (g eyeGeometry) coverage(scale, ox, oy float64, bounds image.Rectangle) *image.Alpha {
+	polygons := func(primitives []eyePrimitive) [][]vector.Point {
+		var polygons [][]vector.Point
+		for _, p := range primitives {
+			lines := vector.MustParsePath(p.path(0, 0)).
+				Transform(scale, vector.Point{X: ox, Y: oy}).
+				Flatten(shapeTolerance)
+			polygons = append(polygons, vector.Fill(lines)...)
+		}
+		return polygons
+	}
+
+	coverage := vector.Coverage(polygons(g.fills), bounds)
+	if len(g.holes) > 0 {
+		holes := vector.Coverage(polygons(g.holes), bounds)
+		for i, h := range holes.Pix {
+			coverage.Pix[i] = uint8((uint32(coverage.Pix[i])*(255-uint32(h)) + 127) / 255)
+		}
+	}
+
+	return coverage
+}
+
+// newEyeGeometry returns the frame (ball false) or the ball (ball true)
+// of a finder pattern. Asymmetric shapes are drawn for the top-left
+// corner, and mirrored horizontally and/or vertically for the others.
+func newEyeGeometry(shape qrconst.EyeShape, ball, mirrorX, mirrorY bool) eyeGeometry {
+	// The frame spans 7 modules with a hole of 5,
+	// and the ball 3 modules in the middle
+	offset, size := 0., 7.
+	if ball {
+		offset, size = 2, 3
+	}
+
+	mirror := func(radii [4]float64) [4]float64 {
+		if mirrorX {
+			radii = [4]float64{radii[1], radii[0], radii[3], radii[2]}
+		}
+		if mirrorY {
+			radii = [4]float64{radii[3], radii[2], radii[1], radii[0]}
+		}
+		return radii
+	}
+	rect := func(offset, size float64, radii [4]float64) eyeRect {
+		return eyeRect{offset, offset, size, size, mirror(radii)}
+	}
+	uniform := func(radius float64) [4]float64 {
+		radius = max(0, radius)
+		return [4]float64{radius, radius, radius, radius}
+	}
+	// Leaves are sharp on the diagonal pointing to the symbol center
+	leaf := func(radius float64) [4]float64 {
+		radius = max(0, radius)
+		return [4]float64{0, radius, 0, radius}
+	}
+	dots := func(ring bool) eyeGeometry {
+		var g eyeGeometry
+		for j := range int(size) {
+			for i := range int(size) {
+				edge := i == 0 || j == 0 || i == int(size)-1 || j == int(size)-1
+				if !ring || edge {
+					g.fills = append(g.fills, eyeRect{offset + float64(i) + .05, offset + float64(j) + .05, .9, .9, uniform(.45)})
+				}
+			}
+		}
+		return g
+	}
+
+	var g eyeGeometry
+	switch shape {
+	case qrconst.EyeRoundedSquare:
+		g.fills = []eyePrimitive{rect(offset, size, uniform(size*.3))}
+		if !ball {
+			g.holes = []eyePrimitive{rect(offset+1, size-2, uniform(size*.3-1))}
+		}
+	case qrconst.EyeCircle:
+		g.fills = []eyePrimitive{rect(offset, size, uniform(size/2))}
+		if !ball {
+			g.holes = []eyePrimitive{rect(offset+1, size-2, uniform(size/2-1))}
+		}
+	case qrconst.EyeLeaf:
+		g.fills = []eyePrimitive{rect(offset, size, leaf(size/2))}
+		if !ball {
+			g.holes = []eyePrimitive{rect(offset+1, size-2, leaf(size/2-1))}
+		}
+	case qrconst.EyeDotted:
+		g = dots(!ball)
+	case qrconst.EyeCushion:
+		g.fills = []eyePrimitive{eyeSuperellipse{3.5, 3.5, size / 2, 4}}
+		if !ball {
+			g.holes = []eyePrimitive{eyeSuperellipse{3.5, 3.5, size/2 - 1, 4}}
+		}
+	default:
+		g.fills = []eyePrimitive{rect(offset, size, uniform(0))}
+		if !ball {
+			g.holes = []eyePrimitive{rect(offset+1, size-2, uniform(0))}
+		}
+	}
+
+	return g
+}
+
+// finderEye is a finder pattern of the symbol, with its top-left
+// module and the mirroring of its shapes.
+type finderEye struct {
+	x, y             int
+	mirrorX, mirrorY bool
+}
+
+// finderEyes returns the finder patterns present in the QR Code.
+func finderEyes(qr qrcode.QRCode) []finderEye {
+	last := qr.Size - 7
+	var eyes []finderEye
+	for _, eye := range []finderEye{
+		{0, 0, false, false},
+		{last, 0, true, false},
+		{0, last, false, true},
+	} {
+		if qr.Patterns[eye.y][eye.x].IsFinder() {
+			eyes = append(eyes, eye)
+		}
+	}
+
+	return eyes
+}
+
+// clearFinders returns a copy of the QR Code with the finder modules
+// turned light, to be drawn separately as whole shapes.
+func clearFinders(qr qrcode.QRCode) qrcode.QRCode {
+	modules := make([][]bool, len(qr.Modules))
+	for y, row := range qr.Modules {
+		modules[y] = append([]bool(nil), row...)
+		for x := range row {
+			if qr.Patterns[y][x].IsFinder() {
+				modules[y][x] = false
+			}
+		}
+	}
+
+	qr.Modules = modules
+	return qr
+}
+
+// drawEyes draws the frames and the balls of the finder patterns.
+func (r *QRRenderer) drawEyes(img *image.RGBA, qr qrcode.QRCode, scale, margin int) {
+	style := r.finderStyle
+	framePaint := pixelPaint(r.foregroundColor, r.foregroundPaint, qr.Size*scale, margin)
+	if style.FrameColor != nil {
+		framePaint = pixelPaint(color.NRGBAModel.Convert(style.FrameColor).(color.NRGBA), nil, qr.Size*scale, margin)
+	}
+	ballPaint := pixelPaint(r.foregroundColor, r.foregroundPaint, qr.Size*scale, margin)
+	if style.BallColor != nil {
+		ballPaint = pixelPaint(color.NRGBAModel.Convert(style.BallColor).(color.NRGBA), nil, qr.Size*scale, margin)
+	}
+	frameImage := style.Frame == qrconst.EyeImage && style.FrameImage != nil
+	ballImage := style.Ball == qrconst.EyeImage && style.BallImage != nil
+
+	for _, eye := range finderEyes(qr) {
+		frame := newEyeGeometry(style.Frame, false, eye.mirrorX, eye.mirrorY)
+		ball := newEyeGeometry(style.Ball, true, eye.mirrorX, eye.mirrorY)
+		startX, startY := margin+eye.x*scale, margin+eye.y*scale
+
+		if frameImage {
+			xdraw.CatmullRom.Scale(
+				img, image.Rect(startX, startY, startX+7*scale, startY+7*scale),
+				style.FrameImage, style.FrameImage.Bounds(),
+				draw.Over, nil,
+			)
+		}
+		bounds := image.Rect(startX, startY, startX+7*scale, startY+7*scale)
+		ox, oy := float64(startX), float64(startY)
+		layers := []struct {
+			geometry eyeGeometry
+			paint    func(px, py int) color.RGBA
+			skip     bool
+		}{
+			{frame, framePaint, frameImage},
+			{ball, ballPaint, ballImage},
+		}
+		for _, layer := range layers {
+			if layer.skip {
+				continue
+			}
+			coverage := layer.geometry.coverage(float64(scale), ox, oy, bounds)
+			blendCoverage(img, coverage, layer.paint, bounds)
+		}
+		if ballImage {
+			xdraw.CatmullRom.Scale(
+				img, image.Rect(startX+2*scale, startY+2*scale, startX+5*scale, startY+5*scale),
+				style.BallImage, style.BallImage.Bounds(),
+				draw.Over, nil,
+			)
+		}
+	}
+}
+
+// writeEyesSVG writes the frames and the balls of the finder patterns.
+// The symbol starts at (origin, origin) in user units.
+func (r *QRRenderer) writeEyesSVG(w io.Writer, qr qrcode.QRCode, origin float64) error {
+	style := r.finderStyle
+	fill := func(c color.Color) string {
+		if c != nil {
+			return "rgba(" + rgbaComponents(color.NRGBAModel.Convert(c).(color.NRGBA)) + ")"
+		}
+		return r.svgForegroundFill()
+	}
+	writeImage := func(img image.Image, x, y, size float64) error {
+		href, err := pngDataURI(img)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(
+			w, "\t<image x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" href=\"%s\" preserveAspectRatio=\"none\"/>\n",
+			svgNum(x), svgNum(y), svgNum(size), svgNum(size), html.EscapeString(href),
+		)
+		return nil
+	}
+
+	for _, eye := range finderEyes(qr) {
+		ox, oy := origin+float64(eye.x), origin+float64(eye.y)
+
+		if style.Frame == qrconst.EyeImage && style.FrameImage != nil {
+			if err := writeImage(style.FrameImage, ox, oy, 7); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(
+				w, "\t<path%s d=\"%s\" fill-rule=\"evenodd\" fill=\"%s\"/>\n",
+				r.svgClassAttr("finder-frame"),
+				newEyeGeometry(style.Frame, false, eye.mirrorX, eye.mirrorY).path(ox, oy),
+				fill(style.FrameColor),
+			)
+		}
+
+		if style.Ball == qrconst.EyeImage && style.BallImage != nil {
+			if err := writeImage(style.BallImage, ox+2, oy+2, 3); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(
+				w, "\t<path%s d=\"%s\" fill=\"%s\"/>\n",
+				r.svgClassAttr("finder-ball"),
+				newEyeGeometry(style.Ball, true, eye.mirrorX, eye.mirrorY).path(ox, oy),
+				fill(style.BallColor),
+			)
+		}
+	}
+
+	return nil
+}
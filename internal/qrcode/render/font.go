@@ -0,0 +1,139 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font is a TrueType or OpenType font for captions.
+type Font struct {
+	font   *sfnt.Font
+	family string
+	bold   bool
+	italic bool
+}
+
+// ParseFont parses a TTF or OTF font file, e.g. read with os.ReadFile.
+// Font collections (TTC and OTC files) are not supported.
+func ParseFont(data []byte) (*Font, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+
+	var buf sfnt.Buffer
+	family, _ := f.Name(&buf, sfnt.NameIDFamily)
+	subfamily, _ := f.Name(&buf, sfnt.NameIDSubfamily)
+	return &Font{
+		font:   f,
+		family: family,
+		bold:   strings.Contains(subfamily, "Bold"),
+		italic: strings.Contains(subfamily, "Italic") || strings.Contains(subfamily, "Oblique"),
+	}, nil
+}
+
+// defaultFont returns the bundled font, Go Bold.
+var defaultFont = sync.OnceValue(func() *Font {
+	f, err := ParseFont(gobold.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// unitsPerEm returns the size at which the glyphs
+// are loaded in font units, in 26.6 fixed point.
+func (f *Font) unitsPerEm() fixed.Int26_6 {
+	return fixed.Int26_6(f.font.UnitsPerEm()) << 6
+}
+
+// text returns the outlines of the glyphs of s, at size user units per
+// em, with the baseline starting at (0, 0), and the advance of the text.
+// Characters missing from the font are drawn as its missing glyph.
+func (f *Font) text(s string, size float64) (vector.Path, float64) {
+	var buf sfnt.Buffer
+	ppem := f.unitsPerEm()
+	scale := size / float64(ppem)
+	pt := func(p fixed.Point26_6, x float64) vector.Point {
+		return vector.Point{X: x + float64(p.X)*scale, Y: float64(p.Y) * scale}
+	}
+
+	var (
+		path    vector.Path
+		advance float64
+		prev    sfnt.GlyphIndex
+	)
+	for i, r := range s {
+		glyph, err := f.font.GlyphIndex(&buf, r)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			// Fonts without kerning return an error
+			if kern, err := f.font.Kern(&buf, prev, glyph, ppem, font.HintingNone); err == nil {
+				advance += float64(kern) * scale
+			}
+		}
+		prev = glyph
+
+		segments, err := f.font.LoadGlyph(&buf, glyph, ppem, nil)
+		if err != nil {
+			continue
+		}
+		for j, seg := range segments {
+			var out vector.Segment
+			switch seg.Op {
+			case sfnt.SegmentOpMoveTo:
+				if j > 0 {
+					path = append(path, vector.Segment{Op: vector.OpClose})
+				}
+				out = vector.Segment{Op: vector.OpMoveTo, Points: [3]vector.Point{pt(seg.Args[0], advance)}}
+			case sfnt.SegmentOpLineTo:
+				out = vector.Segment{Op: vector.OpLineTo, Points: [3]vector.Point{pt(seg.Args[0], advance)}}
+			case sfnt.SegmentOpQuadTo:
+				out = vector.Segment{Op: vector.OpQuadTo, Points: [3]vector.Point{
+					pt(seg.Args[0], advance), pt(seg.Args[1], advance),
+				}}
+			case sfnt.SegmentOpCubeTo:
+				out = vector.Segment{Op: vector.OpCubeTo, Points: [3]vector.Point{
+					pt(seg.Args[0], advance), pt(seg.Args[1], advance), pt(seg.Args[2], advance),
+				}}
+			}
+			path = append(path, out)
+		}
+		if len(segments) > 0 {
+			path = append(path, vector.Segment{Op: vector.OpClose})
+		}
+
+		if adv, err := f.font.GlyphAdvance(&buf, glyph, ppem, font.HintingNone); err == nil {
+			advance += float64(adv) * scale
+		}
+	}
+
+	return path, advance
+}
+
+// capHeight returns the height of the capital
+// letters, at size user units per em.
+func (f *Font) capHeight(size float64) float64 {
+	var buf sfnt.Buffer
+	ppem := f.unitsPerEm()
+	m, err := f.font.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return .7 * size
+	}
+
+	// Fonts without an OS/2 table have no cap height
+	h := m.CapHeight
+	if h <= 0 {
+		h = m.Ascent * 7 / 10
+	}
+	return float64(h) / float64(ppem) * size
+}
@@ -0,0 +1,430 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// FrameOptions controls the frame drawn around the quiet zone, and its
+// caption, e.g. a call to action like "SCAN ME" or the URL encoded in
+// the symbol. Lengths are in modules.
+type FrameOptions struct {
+	// Shape is the shape of the frame. FrameNone only draws the caption.
+	Shape qrconst.FrameShape
+	// Thickness is the width of the frame around the quiet zone.
+	Thickness float64
+	// Color is the color of the frame. A nil color uses the foreground.
+	Color color.Color
+	// Caption is a line of text drawn above or below the symbol.
+	Caption  string
+	Position qrconst.CaptionPosition
+	// Font is the font of the caption. A nil font uses the bundled Go
+	// Bold font.
+	Font *Font
+	// FontSize is the size of the caption, in modules per em. Captions
+	// wider than the quiet zone are shrunk to fit.
+	FontSize float64
+	// TextColor is the color of the caption. A nil color cuts the caption
+	// out of the border, rounded box and ticket frames, showing the
+	// background through, and uses the frame color for the others.
+	TextColor color.Color
+	// SVGText writes the caption of SVG output as a text element, which
+	// can be selected and searched, in the font family of the font.
+	// Viewers lacking the font substitute another one, stretched to the
+	// width of the caption. Otherwise the glyphs are written as paths.
+	SVGText bool
+}
+
+func DefaultFrameOptions() FrameOptions {
+	return FrameOptions{
+		Shape:     qrconst.FrameBorder,
+		Thickness: 1,
+		Caption:   "SCAN ME",
+		Position:  qrconst.CaptionBelow,
+		FontSize:  4,
+	}
+}
+
+// WithFrame draws a frame and a caption around the symbol, extending the
+// raster and SVG output beyond the output size. PDF and EPS output are
+// left unframed.
+func (r *QRRenderer) WithFrame(
+	opts FrameOptions,
+) *QRRenderer {
+	r.frameOptions = &opts
+	if opts.Shape == qrconst.FrameNone && opts.Caption == "" {
+		r.frameOptions = nil
+	}
+	return r
+}
+
+// ReadableURL shortens a URL for a caption, leaving
+// out the scheme, the "www." prefix and a trailing slash.
+func ReadableURL(url string) string {
+	for _, scheme := range []string{"https://", "http://"} {
+		if len(url) >= len(scheme) && strings.EqualFold(url[:len(scheme)], scheme) {
+			url = url[len(scheme):]
+			break
+		}
+	}
+	url = strings.TrimPrefix(url, "www.")
+	return strings.TrimSuffix(url, "/")
+}
+
+// frameLayout is the frame and the caption of a symbol,
+// in modules from the top-left corner of the symbol.
+type frameLayout struct {
+	// left, top, right and bottom are the widths
+	// of the frame and caption beyond the quiet zone
+	left, top, right, bottom float64
+	shape                    eyeGeometry
+	color                    color.NRGBA
+	// caption is the outline of the caption, starting at x on the
+	// baseline y, with the given font size and width
+	caption                      vector.Path
+	x, y, fontSize, captionWidth float64
+	captionColor                 color.NRGBA
+	// knockout cuts the caption out of the frame
+	knockout bool
+}
+
+// frameLayout lays out the frame for modules of scale pixels. Its
+// lengths are rounded to whole pixels, so that it lines up with the
+// edges of the image.
+func (r *QRRenderer) frameLayout(qr qrcode.QRCode, scale int) *frameLayout {
+	opts := r.frameOptions
+	s := float64(scale)
+	snap := func(v float64) float64 {
+		return math.Round(max(0, v)*s) / s
+	}
+
+	n, q := float64(qr.Size), float64(r.quietZone)
+	t := snap(opts.Thickness)
+	if opts.Shape == qrconst.FrameNone {
+		t = 0
+	}
+	fontSize := max(0, opts.FontSize)
+	band := 0.
+	// Tickets keep their stub without a caption
+	if opts.Caption != "" || opts.Shape == qrconst.FrameTicket {
+		band = snap(1.5 * fontSize)
+	}
+	tail := 0.
+	if opts.Shape == qrconst.FrameSpeechBubble {
+		tail = snap(max(2*t, 1))
+	}
+
+	f := &frameLayout{
+		left: t, top: t, right: t, bottom: t + tail + band,
+		color: r.foregroundColor,
+	}
+	if opts.Color != nil {
+		f.color = color.NRGBAModel.Convert(opts.Color).(color.NRGBA)
+	}
+
+	// The frame is laid out for a caption below the symbol,
+	// and mirrored vertically for a caption above it
+	above := opts.Position == qrconst.CaptionAbove
+	if above {
+		f.top, f.bottom = f.bottom, f.top
+	}
+	my := func(y float64) float64 {
+		if above {
+			return n - y
+		}
+		return y
+	}
+	rect := func(x0, y0, x1, y1, radius float64) eyeRect {
+		y0, y1 = min(my(y0), my(y1)), max(my(y0), my(y1))
+		return eyeRect{x0, y0, x1 - x0, y1 - y0, [4]float64{radius, radius, radius, radius}}
+	}
+
+	// The quiet zone, and the box around it
+	x0, y0, x1, y1 := -q, -q, n+q, n+q
+	bx0, by0, bx1, by1 := x0-t, y0-t, x1+t, y1+t
+	// The caption is centered in its band
+	center := y1 + (t+band)/2
+	switch opts.Shape {
+	case qrconst.FrameNone:
+		center = y1 + band/2
+	case qrconst.FrameBorder, qrconst.FrameTicket:
+		f.shape.fills = []eyePrimitive{rect(bx0, by0, bx1, by1+band, 0)}
+		f.shape.holes = []eyePrimitive{rect(x0, y0, x1, y1, 0)}
+	case qrconst.FrameRoundedBox:
+		f.shape.fills = []eyePrimitive{rect(bx0, by0, bx1, by1+band, 2*t)}
+		f.shape.holes = []eyePrimitive{rect(x0, y0, x1, y1, min(t, q))}
+	case qrconst.FrameSpeechBubble:
+		// The tail points from the bubble to the caption
+		f.shape.fills = []eyePrimitive{
+			rect(bx0, by0, bx1, by1, 2*t),
+			polygonPrimitive{{X: n/2 - tail, Y: my(by1)}, {X: n/2 + tail, Y: my(by1)}, {X: n / 2, Y: my(by1 + tail)}},
+		}
+		f.shape.holes = []eyePrimitive{rect(x0, y0, x1, y1, min(t, q))}
+		center = by1 + tail + band/2
+	}
+	if opts.Shape == qrconst.FrameTicket {
+		// The caption is on the stub, past the perforation
+		f.shape.holes = append(f.shape.holes, ticketPerforation(bx0, bx1, my(y1+t/2), t)...)
+		center = by1 + band/2
+	}
+
+	if opts.Caption == "" {
+		return f
+	}
+
+	font := opts.Font
+	if font == nil {
+		font = defaultFont()
+	}
+	path, width := font.text(opts.Caption, fontSize)
+	// Leave half an em on both sides
+	if room := x1 - x0 - fontSize; width > room && width > 0 {
+		shrink := max(0, room) / width
+		path = path.Transform(shrink, vector.Point{})
+		fontSize *= shrink
+		width *= shrink
+	}
+	f.x = (n - width) / 2
+	f.y = my(center) + font.capHeight(fontSize)/2
+	f.caption = path.Transform(1, vector.Point{X: f.x, Y: f.y})
+	f.fontSize, f.captionWidth = fontSize, width
+
+	f.captionColor = f.color
+	if opts.TextColor != nil {
+		f.captionColor = color.NRGBAModel.Convert(opts.TextColor).(color.NRGBA)
+	} else {
+		switch opts.Shape {
+		case qrconst.FrameBorder, qrconst.FrameRoundedBox, qrconst.FrameTicket:
+			f.knockout = true
+		}
+	}
+
+	return f
+}
+
+// ticketPerforation returns the holes of the stub of a ticket frame of
+// the given thickness, spanning from x0 to x1: half discs notched into
+// both sides and a dashed line, centered on y.
+func ticketPerforation(x0, x1, y, thickness float64) []eyePrimitive {
+	radius := .6 * thickness
+	holes := []eyePrimitive{
+		halfDiscPrimitive{x0, y, radius, 1},
+		halfDiscPrimitive{x1, y, radius, -1},
+	}
+
+	dash, gap := thickness, thickness/2
+	start, end := x0+radius+gap, x1-radius-gap
+	count := math.Floor((end - start + gap) / (dash + gap))
+	if count < 1 {
+		return holes
+	}
+	x := start + (end-start-count*dash-(count-1)*gap)/2
+	for range int(count) {
+		holes = append(holes, eyeRect{x: x, y: y - thickness/6, w: dash, h: thickness / 3})
+		x += dash + gap
+	}
+
+	return holes
+}
+
+// polygonPrimitive is a polygon, in modules.
+type polygonPrimitive []vector.Point
+
+func (p polygonPrimitive) path(ox, oy float64) string {
+	var b strings.Builder
+	for i, pt := range p {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&b, "%s %s %s ", cmd, svgNum(ox+pt.X), svgNum(oy+pt.Y))
+	}
+	b.WriteString("Z")
+
+	return b.String()
+}
+
+// halfDiscPrimitive is half a disc centered on (x, y), on the right of
+// its diameter for a positive direction, on the left for a negative one.
+type halfDiscPrimitive struct {
+	x, y, r, dir float64
+}
+
+func (h halfDiscPrimitive) path(ox, oy float64) string {
+	x, y0, y1 := ox+h.x, oy+h.y-h.r, oy+h.y+h.r
+	if h.dir < 0 {
+		y0, y1 = y1, y0
+	}
+	return fmt.Sprintf(
+		"M %s %s A %s %s 0 0 1 %s %s Z",
+		svgNum(x), svgNum(y0), svgNum(h.r), svgNum(h.r), svgNum(x), svgNum(y1),
+	)
+}
+
+// renderFramedImage renders the symbol, surrounded by
+// its frame and caption when the renderer has one.
+func (r *QRRenderer) renderFramedImage(qr qrcode.QRCode) image.Image {
+	img := r.renderImage(qr)
+	if r.frameOptions == nil {
+		return img
+	}
+
+	return r.drawFrame(img.(*image.RGBA), qr)
+}
+
+// drawFrame returns the symbol image extended by its frame and caption.
+func (r *QRRenderer) drawFrame(symbol *image.RGBA, qr qrcode.QRCode) *image.RGBA {
+	scale, margin, imgSize := r.layout(qr)
+	f := r.frameLayout(qr, scale)
+	s := float64(scale)
+	pixels := func(v float64) int {
+		return int(math.Round(v * s))
+	}
+	left, top := pixels(f.left), pixels(f.top)
+	bounds := image.Rect(0, 0, left+imgSize+pixels(f.right), top+imgSize+pixels(f.bottom))
+	img := image.NewRGBA(bounds)
+
+	// The background extends under the frame, with
+	// its gradient still laid out across the symbol
+	bg := pixelPaint(r.backgroundColor, r.backgroundPaint, qr.Size*scale, margin)
+	parallelRows(bounds.Dy(), func(y0, y1 int) {
+		for py := y0; py < y1; py++ {
+			row := img.Pix[img.PixOffset(0, py):]
+			for px := range bounds.Dx() {
+				c := bg(px-left, py-top)
+				d := row[4*px : 4*px+4 : 4*px+4]
+				d[0], d[1], d[2], d[3] = c.R, c.G, c.B, c.A
+			}
+		}
+	})
+	draw.Draw(img, symbol.Bounds().Add(image.Pt(left, top)), symbol, image.Point{}, draw.Src)
+
+	ox, oy := float64(left+margin), float64(top+margin)
+	coverage := f.shape.coverage(s, ox, oy, bounds)
+	var caption *image.Alpha
+	if f.caption != nil {
+		lines := f.caption.Transform(s, vector.Point{X: ox, Y: oy}).Flatten(shapeTolerance)
+		caption = vector.Coverage(vector.Fill(lines), bounds)
+		if f.knockout {
+			for i, c := range caption.Pix {
+				coverage.Pix[i] = uint8((uint32(coverage.Pix[i])*(255-uint32(c)) + 127) / 255)
+			}
+		}
+	}
+
+	blendCoverage(img, coverage, pixelPaint(f.color, nil, 0, 0), bounds)
+	if caption != nil && !f.knockout {
+		blendCoverage(img, caption, pixelPaint(f.captionColor, nil, 0, 0), bounds)
+	}
+
+	return img
+}
+
+// writeFrameSVG writes the frame and the caption of the SVG output. The
+// symbol starts at (origin, origin) in user units, and view is the
+// rectangle attributes of the view box.
+func (r *QRRenderer) writeFrameSVG(w io.Writer, f *frameLayout, origin float64, view string) {
+	var caption string
+	if f.caption != nil {
+		fill := "rgba(" + rgbaComponents(f.captionColor) + ")"
+		if f.knockout {
+			fill = "rgb(0,0,0)"
+		}
+		caption = r.svgCaption(f, origin, fill)
+	}
+
+	d := f.shape.path(origin, origin)
+	fill := "rgba(" + rgbaComponents(f.color) + ")"
+	switch {
+	case f.knockout:
+		// The caption is cut out of the frame through a mask
+		fmt.Fprintf(
+			w, `	<mask id="%s" maskUnits="userSpaceOnUse" %s>
+		<rect %s fill="rgb(255,255,255)"/>
+		%s
+	</mask>
+	<path d="%s" fill="%s" fill-rule="evenodd" mask="url(#%s)"/>
+`,
+			r.svgID("frame__mask"), view, view, caption, d, fill, r.svgID("frame__mask"),
+		)
+	case d != "":
+		fmt.Fprintf(w, "\t<path d=\"%s\" fill=\"%s\" fill-rule=\"evenodd\"/>\n", d, fill)
+		fallthrough
+	default:
+		if caption != "" {
+			fmt.Fprintf(w, "\t%s\n", caption)
+		}
+	}
+}
+
+// svgCaption returns the caption as an SVG element filled with fill,
+// either a text element or the outlines of its glyphs.
+func (r *QRRenderer) svgCaption(f *frameLayout, origin float64, fill string) string {
+	opts := r.frameOptions
+	if !opts.SVGText {
+		return fmt.Sprintf(`<path d="%s" fill="%s"/>`, svgPathData(f.caption, origin, origin), fill)
+	}
+
+	font := opts.Font
+	if font == nil {
+		font = defaultFont()
+	}
+	family := "sans-serif"
+	if font.family != "" {
+		family = "'" + font.family + "', " + family
+	}
+	style := ""
+	if font.bold {
+		style += ` font-weight="bold"`
+	}
+	if font.italic {
+		style += ` font-style="italic"`
+	}
+
+	return fmt.Sprintf(
+		`<text x="%s" y="%s" font-family="%s" font-size="%s"%s textLength="%s" fill="%s">%s</text>`,
+		svgNum(origin+f.x), svgNum(origin+f.y), html.EscapeString(family), svgNum(f.fontSize), style,
+		svgNum(f.captionWidth), fill, html.EscapeString(opts.Caption),
+	)
+}
+
+// svgPathData returns the SVG path data of p, offset by (ox, oy).
+func svgPathData(p vector.Path, ox, oy float64) string {
+	var b strings.Builder
+	point := func(pt vector.Point) {
+		fmt.Fprintf(&b, " %s %s", svgNum(ox+pt.X), svgNum(oy+pt.Y))
+	}
+	for _, seg := range p {
+		switch seg.Op {
+		case vector.OpMoveTo:
+			b.WriteString(" M")
+			point(seg.Points[0])
+		case vector.OpLineTo:
+			b.WriteString(" L")
+			point(seg.Points[0])
+		case vector.OpQuadTo:
+			b.WriteString(" Q")
+			point(seg.Points[0])
+			point(seg.Points[1])
+		case vector.OpCubeTo:
+			b.WriteString(" C")
+			point(seg.Points[0])
+			point(seg.Points[1])
+			point(seg.Points[2])
+		case vector.OpClose:
+			b.WriteString(" Z")
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
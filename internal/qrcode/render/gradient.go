@@ -0,0 +1,293 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// conicWedges is the number of solid wedges used
+// approximating a conic gradient in SVG and print output.
+const conicWedges = 180
+
+type GradientStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Gradient is a color gradient spanning the whole symbol, excluding the
+// quiet zone. Positions and radii are fractions of the symbol size, with
+// (0, 0) at the top-left module and (1, 1) at the bottom-right corner of
+// the bottom-right module. Angles are in degrees, clockwise from the
+// positive x axis.
+type Gradient struct {
+	Type    qrconst.GradientType
+	Stops   []GradientStop
+	Angle   float64 // linear: direction, conic: start angle
+	CenterX float64 // radial and conic
+	CenterY float64 // radial and conic
+	Radius  float64 // radial
+}
+
+// NewLinearGradient returns a gradient running across the symbol in the
+// direction of angle, e.g. 0 for left to right and 90 for top to bottom.
+func NewLinearGradient(angle float64, stops ...GradientStop) Gradient {
+	return Gradient{
+		Type:  qrconst.LinearGradient,
+		Stops: stops,
+		Angle: angle,
+	}
+}
+
+func NewRadialGradient(centerX, centerY, radius float64, stops ...GradientStop) Gradient {
+	return Gradient{
+		Type:    qrconst.RadialGradient,
+		Stops:   stops,
+		CenterX: centerX,
+		CenterY: centerY,
+		Radius:  radius,
+	}
+}
+
+// NewConicGradient returns a gradient sweeping clockwise
+// around the center, starting at angle.
+func NewConicGradient(centerX, centerY, angle float64, stops ...GradientStop) Gradient {
+	return Gradient{
+		Type:    qrconst.ConicGradient,
+		Stops:   stops,
+		Angle:   angle,
+		CenterX: centerX,
+		CenterY: centerY,
+	}
+}
+
+// gradientPaint is a gradient with its stops
+// sorted, clamped and converted to straight alpha.
+type gradientPaint struct {
+	Gradient
+	offsets []float64
+	colors  []color.NRGBA
+	inks    []ink
+	// cmyk is set when a stop is a CMYK or spot color, so that
+	// print output interpolates the stops in CMYK
+	cmyk bool
+}
+
+func newGradientPaint(g Gradient) *gradientPaint {
+	stops := slices.Clone(g.Stops)
+	slices.SortStableFunc(stops, func(a, b GradientStop) int {
+		switch {
+		case a.Offset < b.Offset:
+			return -1
+		case a.Offset > b.Offset:
+			return 1
+		}
+		return 0
+	})
+
+	p := &gradientPaint{Gradient: g}
+	for _, stop := range stops {
+		i := ink{}
+		if stop.Color != nil {
+			i = newInk(stop.Color)
+		}
+		p.offsets = append(p.offsets, min(1, max(0, stop.Offset)))
+		p.colors = append(p.colors, i.rgb)
+		p.inks = append(p.inks, i)
+		p.cmyk = p.cmyk || i.kind != rgbInk
+	}
+
+	return p
+}
+
+// linearDirection returns the unit direction of a linear gradient,
+// and the length of the symbol projected onto it.
+func (p *gradientPaint) linearDirection() (dx, dy, length float64) {
+	rad := p.Angle * math.Pi / 180
+	dx, dy = math.Cos(rad), math.Sin(rad)
+	return dx, dy, math.Abs(dx) + math.Abs(dy)
+}
+
+// position returns the gradient position of the point (u, v),
+// expressed in fractions of the symbol size.
+func (p *gradientPaint) position(u, v float64) float64 {
+	switch p.Type {
+	case qrconst.RadialGradient:
+		if p.Radius <= 0 {
+			return 1
+		}
+		return math.Hypot(u-p.CenterX, v-p.CenterY) / p.Radius
+	case qrconst.ConicGradient:
+		deg := math.Atan2(v-p.CenterY, u-p.CenterX)*180/math.Pi - p.Angle
+		deg = math.Mod(deg, 360)
+		if deg < 0 {
+			deg += 360
+		}
+		return deg / 360
+	default:
+		dx, dy, length := p.linearDirection()
+		return ((u-.5)*dx+(v-.5)*dy)/length + .5
+	}
+}
+
+// colorAt interpolates the stops at gradient position t. Like SVG,
+// the color and alpha are interpolated separately, and positions
+// outside the stops take the color of the nearest stop.
+func (p *gradientPaint) colorAt(t float64) color.NRGBA {
+	if len(p.offsets) == 0 {
+		return color.NRGBA{}
+	}
+
+	i, f := p.stopAt(t)
+	if f == 0 {
+		return p.colors[i]
+	}
+	a, b := p.colors[i], p.colors[i+1]
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*f + .5)
+	}
+
+	return color.NRGBA{
+		lerp(a.R, b.R),
+		lerp(a.G, b.G),
+		lerp(a.B, b.B),
+		lerp(a.A, b.A),
+	}
+}
+
+// inkAt interpolates the stops at gradient position t like colorAt,
+// in CMYK when a stop is a CMYK or spot color.
+func (p *gradientPaint) inkAt(t float64) ink {
+	if !p.cmyk {
+		return newInk(p.colorAt(t))
+	}
+
+	i, f := p.stopAt(t)
+	c := p.inks[i].process()
+	if f > 0 {
+		c = lerpCMYK(c, p.inks[i+1].process(), f)
+	}
+	return ink{kind: cmykInk, rgb: p.colorAt(t), cmyk: c}
+}
+
+// stopAt returns the stop i at or before gradient position t, and the
+// fraction f of the way to the next stop, zero past the last stop.
+func (p *gradientPaint) stopAt(t float64) (i int, f float64) {
+	n := len(p.offsets)
+	switch {
+	case t <= p.offsets[0]:
+		return 0, 0
+	case t >= p.offsets[n-1]:
+		return n - 1, 0
+	}
+
+	i = 1
+	for p.offsets[i] < t {
+		i++
+	}
+	span := p.offsets[i] - p.offsets[i-1]
+	if span <= 0 {
+		return i, 0
+	}
+	return i - 1, (t - p.offsets[i-1]) / span
+}
+
+func (p *gradientPaint) at(u, v float64) color.NRGBA {
+	return p.colorAt(p.position(u, v))
+}
+
+// writeSVGDef writes the gradient as an SVG paint server with the given
+// id. The symbol spans size user units from (origin, origin), and the
+// whole image spans viewBoxSize user units from (viewBoxOrigin,
+// viewBoxOrigin). Conic gradients have no SVG equivalent, and are
+// written as a pattern of solid wedges covering the image instead.
+func (p *gradientPaint) writeSVGDef(w io.Writer, id string, origin, size, viewBoxOrigin, viewBoxSize float64) {
+	coord := svgNum
+	cx, cy := origin+p.CenterX*size, origin+p.CenterY*size
+
+	switch p.Type {
+	case qrconst.RadialGradient:
+		fmt.Fprintf(
+			w, "\t\t<radialGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" cx=\"%s\" cy=\"%s\" r=\"%s\">\n",
+			id, coord(cx), coord(cy), coord(p.Radius*size),
+		)
+		p.writeSVGStops(w)
+		fmt.Fprint(w, "\t\t</radialGradient>\n")
+	case qrconst.ConicGradient:
+		fmt.Fprintf(
+			w, "\t\t<pattern id=\"%s\" patternUnits=\"userSpaceOnUse\" x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\">\n",
+			id, coord(viewBoxOrigin), coord(viewBoxOrigin), coord(viewBoxSize), coord(viewBoxSize),
+		)
+		for _, wedge := range gradientWedges(p, origin, size, viewBoxSize) {
+			a, b, c := wedge.points[0], wedge.points[1], wedge.points[2]
+			fmt.Fprintf(
+				w, "\t\t\t<path d=\"M %s %s L %s %s L %s %s Z\" fill=\"rgba(%s)\"/>\n",
+				coord(a.X), coord(a.Y), coord(b.X), coord(b.Y), coord(c.X), coord(c.Y),
+				rgbaComponents(wedge.ink.rgb),
+			)
+		}
+		fmt.Fprint(w, "\t\t</pattern>\n")
+	default:
+		// Linear gradients always run through the center of the symbol
+		cx, cy = origin+size/2, origin+size/2
+		dx, dy, length := p.linearDirection()
+		half := length * size / 2
+		fmt.Fprintf(
+			w, "\t\t<linearGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\">\n",
+			id,
+			coord(cx-dx*half), coord(cy-dy*half),
+			coord(cx+dx*half), coord(cy+dy*half),
+		)
+		p.writeSVGStops(w)
+		fmt.Fprint(w, "\t\t</linearGradient>\n")
+	}
+}
+
+// gradientWedge is a solid wedge of a conic gradient,
+// from the center of the gradient.
+type gradientWedge struct {
+	ink    ink
+	points [3]vector.Point
+}
+
+// gradientWedges returns the wedges painting a conic gradient over the
+// extent user units around the symbol, which spans size user units
+// from (origin, origin).
+func gradientWedges(p *gradientPaint, origin, size, extent float64) []gradientWedge {
+	center := vector.Point{X: origin + p.CenterX*size, Y: origin + p.CenterY*size}
+	radius := 2 * extent
+	step := 2 * math.Pi / conicWedges
+	// Overlap the wedges slightly to hide seams
+	overlap := step / 4
+	edge := func(angle float64) vector.Point {
+		return vector.Point{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}
+	}
+
+	wedges := make([]gradientWedge, conicWedges)
+	for i := range wedges {
+		start := p.Angle*math.Pi/180 + float64(i)*step
+		wedges[i] = gradientWedge{
+			ink:    p.inkAt((float64(i) + .5) / conicWedges),
+			points: [3]vector.Point{center, edge(start), edge(start + step + overlap)},
+		}
+	}
+
+	return wedges
+}
+
+func (p *gradientPaint) writeSVGStops(w io.Writer) {
+	for i, c := range p.colors {
+		fmt.Fprintf(
+			w, "\t\t\t<stop offset=\"%s\" stop-color=\"rgb(%d,%d,%d)\" stop-opacity=\"%s\"/>\n",
+			strconv.FormatFloat(p.offsets[i], 'f', -1, 64),
+			c.R, c.G, c.B,
+			strconv.FormatFloat(float64(c.A)/255.0, 'f', -1, 64),
+		)
+	}
+}
@@ -0,0 +1,274 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	xdraw "golang.org/x/image/draw"
+)
+
+// HalftoneOptions controls how an image shows through the modules.
+type HalftoneOptions struct {
+	// CenterSize is the width of the center of every module, which
+	// keeps its dark or light value, as a fraction of the module width.
+	// Smaller centers show more of the image, but scan less reliably.
+	CenterSize float64
+	// Dither dithers the image to the foreground and background
+	// colors, instead of showing it in its own colors.
+	Dither bool
+	// Resolution is the number of dithered cells across
+	// every module. Zero dithers every pixel.
+	Resolution int
+}
+
+func DefaultHalftoneOptions() HalftoneOptions {
+	return HalftoneOptions{
+		CenterSize: 1. / 3,
+		Dither:     true,
+		Resolution: 3,
+	}
+}
+
+// WithHalftone blends the image into the symbol, cropped to a square:
+// every message module keeps its value in its center, and shows the
+// image around it. Finder, alignment, timing and the other function
+// patterns are drawn whole. Raster, SVG and PDF output draw the image
+// as a raster image, and EPS output leaves it out. A nil image removes
+// it.
+func (r *QRRenderer) WithHalftone(
+	img image.Image,
+	opts HalftoneOptions,
+) *QRRenderer {
+	r.halftone = img
+	r.halftoneOptions = opts
+	return r
+}
+
+// halftoneCenter returns the start and the end of the
+// center of a module, as a fraction of the module width.
+func (r *QRRenderer) halftoneCenter() (float64, float64) {
+	size := min(1, max(0, r.halftoneOptions.CenterSize))
+	return (1 - size) / 2, (1 + size) / 2
+}
+
+// halftoneCrop returns the largest square centered in b.
+func halftoneCrop(b image.Rectangle) image.Rectangle {
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x0, y0, x0+side, y0+side)
+}
+
+// halftoneImage returns the image as shown by the modules, over the
+// symbol of size pixels, dithered to the module colors or over the
+// background.
+func (r *QRRenderer) halftoneImage(qr qrcode.QRCode, size int) *image.RGBA {
+	opts := r.halftoneOptions
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	bg := pixelPaint(r.backgroundColor, r.backgroundPaint, size, 0)
+
+	if !opts.Dither {
+		for py := range size {
+			for px := range size {
+				img.SetRGBA(px, py, bg(px, py))
+			}
+		}
+		xdraw.CatmullRom.Scale(img, img.Bounds(), r.halftone, halftoneCrop(r.halftone.Bounds()), draw.Over, nil)
+		return img
+	}
+
+	cells := size
+	if opts.Resolution > 0 {
+		cells = qr.Size * opts.Resolution
+	}
+	dark := ditherHalftone(r.halftone, cells)
+	fg := pixelPaint(r.foregroundColor, r.foregroundPaint, size, 0)
+	for py := range size {
+		row := dark[py*cells/size*cells:]
+		for px := range size {
+			if row[px*cells/size] {
+				img.SetRGBA(px, py, fg(px, py))
+			} else {
+				img.SetRGBA(px, py, bg(px, py))
+			}
+		}
+	}
+
+	return img
+}
+
+// ditherHalftone scales the square crop of src to n by n cells, and
+// reports whether every cell is dark, by Floyd-Steinberg dithering of
+// its luminance over white.
+func ditherHalftone(src image.Image, n int) []bool {
+	scaled := image.NewRGBA(image.Rect(0, 0, n, n))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), src, halftoneCrop(src.Bounds()), draw.Src, nil)
+
+	levels := make([]float64, n*n)
+	for i := range levels {
+		p := scaled.Pix[4*i : 4*i+4]
+		levels[i] = qrcode.LuminanceOverWhite(color.RGBA{p[0], p[1], p[2], p[3]})
+	}
+
+	dark := make([]bool, n*n)
+	for y := range n {
+		for x := range n {
+			i := y*n + x
+			level := levels[i]
+			dark[i] = level < 128
+			if !dark[i] {
+				level -= 255
+			}
+
+			// Spread the error to the unvisited neighbors
+			if x+1 < n {
+				levels[i+1] += level * 7 / 16
+			}
+			if y+1 < n {
+				if x > 0 {
+					levels[i+n-1] += level * 3 / 16
+				}
+				levels[i+n] += level * 5 / 16
+				if x+1 < n {
+					levels[i+n+1] += level * 1 / 16
+				}
+			}
+		}
+	}
+
+	return dark
+}
+
+// drawHalftone replaces the message modules of img, except their
+// centers, by the halftone image.
+func (r *QRRenderer) drawHalftone(img *image.RGBA, qr qrcode.QRCode, scale, margin int) {
+	halftone := r.halftoneImage(qr, qr.Size*scale)
+	start, end := r.halftoneCenter()
+	c0 := int(math.Round(start * float64(scale)))
+	c1 := int(math.Round(end * float64(scale)))
+
+	for y, row := range qr.Patterns {
+		for x, pattern := range row {
+			// Like the logo, the image only covers the message modules
+			if !isLogoClearable(pattern) {
+				continue
+			}
+			for dy := range scale {
+				for dx := range scale {
+					if dx >= c0 && dx < c1 && dy >= c0 && dy < c1 {
+						continue
+					}
+					px, py := x*scale+dx, y*scale+dy
+					img.SetRGBA(margin+px, margin+py, halftone.RGBAAt(px, py))
+				}
+			}
+		}
+	}
+}
+
+// halftoneVectorImage returns the halftone image of the SVG and
+// PDF output, with a pixel per dithered cell when dithering by cells.
+func (r *QRRenderer) halftoneVectorImage(qr qrcode.QRCode) *image.RGBA {
+	scale, _, _ := r.layout(qr)
+	if r.halftoneOptions.Dither && r.halftoneOptions.Resolution > 0 {
+		scale = r.halftoneOptions.Resolution
+	}
+	return r.halftoneImage(qr, qr.Size*scale)
+}
+
+// halftoneArea returns the message modules showing the image, and
+// their centers as squares in modules, from the top-left corner
+// of the symbol.
+func (r *QRRenderer) halftoneArea(qr qrcode.QRCode) (modules [][]bool, centers []logoBox) {
+	start, end := r.halftoneCenter()
+	modules = make([][]bool, qr.Size)
+	for y, row := range qr.Patterns {
+		modules[y] = make([]bool, qr.Size)
+		for x, pattern := range row {
+			if !isLogoClearable(pattern) {
+				continue
+			}
+			modules[y][x] = true
+			if end > start {
+				fx, fy := float64(x), float64(y)
+				centers = append(centers, logoBox{fx + start, fy + start, fx + end, fy + end})
+			}
+		}
+	}
+	return modules, centers
+}
+
+// writeHalftoneSVG writes the halftone image, clipped to the message
+// modules minus their centers. The symbol starts at (origin, origin)
+// in user units.
+func (r *QRRenderer) writeHalftoneSVG(w io.Writer, qr qrcode.QRCode, origin float64) error {
+	href, err := pngDataURI(r.halftoneVectorImage(qr))
+	if err != nil {
+		return err
+	}
+
+	// The centers lie inside the modules,
+	// so the even-odd rule cuts them out
+	modules, centers := r.halftoneArea(qr)
+	clip := []string{squareOutline(modules, int(origin), int(origin))}
+	for _, b := range centers {
+		clip = append(clip, fmt.Sprintf(
+			"M %s %s H %s V %s H %s Z",
+			svgNum(origin+b.x0), svgNum(origin+b.y0), svgNum(origin+b.x1), svgNum(origin+b.y1), svgNum(origin+b.x0),
+		))
+	}
+
+	// Dithered images are kept sharp when scaled up
+	style := ""
+	if r.halftoneOptions.Dither {
+		style = ` style="image-rendering:pixelated"`
+	}
+	fmt.Fprintf(w, `	<clipPath id="%s">
+		<path d="%s" clip-rule="evenodd"/>
+	</clipPath>
+	<image x="%s" y="%s" width="%d" height="%d" href="%s" preserveAspectRatio="none" clip-path="url(#%s)"%s/>
+`,
+		r.svgID("halftone__clip"), strings.Join(clip, " "),
+		svgNum(origin), svgNum(origin), qr.Size, qr.Size, html.EscapeString(href),
+		r.svgID("halftone__clip"), style,
+	)
+
+	return nil
+}
+
+// drawHalftonePDF draws the halftone image, clipped to the message
+// modules minus their centers. The symbol starts at (origin, origin)
+// in user units.
+func (r *QRRenderer) drawHalftonePDF(d *pdfDocument, c *pdfContent, qr qrcode.QRCode, origin float64) {
+	rect := func(b logoBox) {
+		c.op(
+			"%s %s %s %s re",
+			pdf.Num(origin+b.x0), pdf.Num(origin+b.y0), pdf.Num(b.x1-b.x0), pdf.Num(b.y1-b.y0),
+		)
+	}
+
+	modules, centers := r.halftoneArea(qr)
+	c.op("q")
+	for y, row := range modules {
+		for x, shown := range row {
+			if shown {
+				rect(logoBox{float64(x), float64(y), float64(x + 1), float64(y + 1)})
+			}
+		}
+	}
+	for _, b := range centers {
+		rect(b)
+	}
+	c.op("W* n")
+	size := float64(qr.Size)
+	d.drawImage(c, r.halftoneVectorImage(qr), origin, origin, size, size)
+	c.op("Q")
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// halftoneSource returns a wide image of color ramps around a disc.
+func halftoneSource() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 150, 100))
+	for y := range 100 {
+		for x := range 150 {
+			dx, dy := float64(x-75)/45, float64(y-50)/45
+			c := color.NRGBA{uint8(x * 255 / 150), uint8(y * 255 / 100), 180, 255}
+			if d := math.Hypot(dx, dy); d < 1 {
+				c = color.NRGBA{uint8(255 * d), 40, 40, 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestHalftone(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("https://example.com/halftone").
+		WithErrorCorrectionLevel(qrconst.H).
+		WithMinVersion(7).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const scale = 12
+
+	for _, opts := range []HalftoneOptions{
+		DefaultHalftoneOptions(),
+		{CenterSize: .5, Dither: true},
+		{CenterSize: .3},
+		{CenterSize: 0, Dither: true, Resolution: 2},
+	} {
+		newRenderer := func() *QRRenderer {
+			return NewRenderer().
+				WithForegroundColor(color.NRGBA{20, 20, 90, 255}).
+				WithModuleSize(scale).
+				WithQuietZone(0)
+		}
+		plain := newRenderer().RenderImage(*qr)
+		img := newRenderer().WithHalftone(halftoneSource(), opts).RenderImage(*qr)
+		if img.Bounds() != plain.Bounds() {
+			t.Fatalf("%+v: got bounds %v, want %v", opts, img.Bounds(), plain.Bounds())
+		}
+
+		start := int(math.Ceil((1 - opts.CenterSize) / 2 * scale))
+		end := int((1 + opts.CenterSize) / 2 * scale)
+		changed := 0
+		for y, row := range qr.Patterns {
+			for x, pattern := range row {
+				// Function patterns are left whole, and message
+				// modules keep their value in their centers
+				for py := range scale {
+					for px := range scale {
+						center := start <= px && px < end && start <= py && py < end
+						p := image.Pt(x*scale+px, y*scale+py)
+						if img.At(p.X, p.Y) == plain.At(p.X, p.Y) {
+							continue
+						}
+						if !pattern.IsMessage() {
+							t.Fatalf("%+v: pixel %v of the function pattern module (%d, %d) changed", opts, p, x, y)
+						}
+						if center {
+							t.Fatalf("%+v: pixel %v of the center of the module (%d, %d) changed", opts, p, x, y)
+						}
+						changed++
+					}
+				}
+			}
+		}
+		// The image shows through
+		if changed == 0 {
+			t.Errorf("%+v: no pixel shows the image", opts)
+		}
+	}
+}
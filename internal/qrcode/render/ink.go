@@ -0,0 +1,220 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// SpotColor is a named spot color, e.g. a Pantone ink, printed at a tint
+// of its full strength. PDF and EPS output paint it as a separation,
+// which devices without the ink print with the CMYK color instead.
+// Other output previews it as the tinted CMYK color.
+type SpotColor struct {
+	Name string
+	// Tint is the strength of the ink, from 0 to 1.
+	Tint float64
+	// CMYK approximates the ink at full strength.
+	CMYK color.CMYK
+}
+
+func (c SpotColor) RGBA() (r, g, b, a uint32) {
+	return c.tinted().RGBA()
+}
+
+// tinted returns the CMYK color approximating the ink at its tint.
+func (c SpotColor) tinted() color.CMYK {
+	tint := min(1, max(0, c.Tint))
+	scale := func(v uint8) uint8 {
+		return uint8(math.Round(float64(v) * tint))
+	}
+
+	return color.CMYK{scale(c.CMYK.C), scale(c.CMYK.M), scale(c.CMYK.Y), scale(c.CMYK.K)}
+}
+
+type inkKind int
+
+const (
+	rgbInk inkKind = iota
+	cmykInk
+	spotInk
+)
+
+// ink is a color as given to the renderer, kept for print output: an
+// RGB color with its opacity, a CMYK color, or a spot color. Its rgb
+// is the color previewed by the other output.
+type ink struct {
+	kind inkKind
+	rgb  color.NRGBA
+	// cmyk is the CMYK color, or the alternate of the spot color
+	cmyk color.CMYK
+	name string
+	tint float64
+}
+
+func newInk(c color.Color) ink {
+	i := ink{rgb: color.NRGBAModel.Convert(c).(color.NRGBA)}
+	switch c := c.(type) {
+	case color.CMYK:
+		i.kind, i.cmyk = cmykInk, c
+	case SpotColor:
+		i.kind, i.cmyk, i.name, i.tint = spotInk, c.CMYK, c.Name, min(1, max(0, c.Tint))
+	}
+
+	return i
+}
+
+// process returns the CMYK color printing the ink,
+// converted from RGB for RGB colors.
+func (i ink) process() color.CMYK {
+	switch i.kind {
+	case cmykInk:
+		return i.cmyk
+	case spotInk:
+		return SpotColor{i.name, i.tint, i.cmyk}.tinted()
+	}
+
+	c, m, y, k := color.RGBToCMYK(i.rgb.R, i.rgb.G, i.rgb.B)
+	return color.CMYK{c, m, y, k}
+}
+
+// key returns a key telling the inks apart, including
+// CMYK and spot colors previewed alike.
+func (i ink) key() string {
+	switch i.kind {
+	case cmykInk:
+		return "cmyk(" + cmykComponents(i.cmyk) + ")"
+	case spotInk:
+		return "spot(" + i.name + " " + cmykComponents(i.cmyk) + " " + svgNum(i.tint) + ")"
+	}
+	return rgbaComponents(i.rgb)
+}
+
+// inks returns the solid colors of the renderer, for CMYK conversion.
+func (r *QRRenderer) inks() []ink {
+	inks := []ink{r.backgroundInk, r.foregroundInk}
+	for _, style := range r.roleStyles {
+		if style.hasColor {
+			inks = append(inks, style.ink)
+		}
+	}
+	if style := r.finderStyle; style != nil {
+		for _, c := range []color.Color{style.FrameColor, style.BallColor} {
+			if c != nil {
+				inks = append(inks, newInk(c))
+			}
+		}
+	}
+	if r.logo != nil && r.logoOptions.PlateColor != nil {
+		inks = append(inks, newInk(r.logoOptions.PlateColor))
+	}
+
+	return inks
+}
+
+// cmykImage converts the raster output, flattened over white paper, to
+// CMYK. Pixels of the solid colors of the renderer, and the antialiased
+// blends between two of them, are printed with the inks of the colors.
+// Other pixels, e.g. of gradients and logos, are converted from RGB.
+func (r *QRRenderer) cmykImage(img image.Image) *image.CMYK {
+	paper := ink{kind: cmykInk, rgb: color.NRGBA{255, 255, 255, 255}}
+	inks := []ink{paper}
+	for _, i := range r.inks() {
+		// Translucent colors show the paper through
+		if i.rgb.A == 255 {
+			inks = append(inks, i)
+		}
+	}
+
+	flat := flatten(img, color.White)
+	b := flat.Bounds()
+	out := image.NewCMYK(b)
+	cache := make(map[color.RGBA]color.CMYK)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			p := color.RGBAModel.Convert(flat.At(x, y)).(color.RGBA)
+			c, ok := cache[p]
+			if !ok {
+				c = inkBlend(p, inks)
+				cache[p] = c
+			}
+			out.SetCMYK(x, y, c)
+		}
+	}
+
+	return out
+}
+
+// inkBlend returns the CMYK color of the opaque pixel p: the ink it
+// matches, the blend of the two inks it lies between, or else its
+// conversion from RGB.
+func inkBlend(p color.RGBA, inks []ink) color.CMYK {
+	// Blends rounded to 8 bits lie within this distance of the exact blend
+	const tolerance = 1.5
+
+	for _, i := range inks {
+		if i.rgb.R == p.R && i.rgb.G == p.G && i.rgb.B == p.B {
+			return i.process()
+		}
+	}
+
+	c, m, y, k := color.RGBToCMYK(p.R, p.G, p.B)
+	best, bestDist := color.CMYK{c, m, y, k}, tolerance
+	vec := func(c color.NRGBA) [3]float64 {
+		return [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+	}
+	pv := vec(color.NRGBA{p.R, p.G, p.B, 255})
+	for ai, a := range inks {
+		for _, b := range inks[ai+1:] {
+			av, bv := vec(a.rgb), vec(b.rgb)
+			var d, e [3]float64
+			var dd, de float64
+			for ch := range 3 {
+				d[ch], e[ch] = bv[ch]-av[ch], pv[ch]-av[ch]
+				dd += d[ch] * d[ch]
+				de += d[ch] * e[ch]
+			}
+			if dd == 0 {
+				continue
+			}
+
+			t := de / dd
+			if t <= 0 || t >= 1 {
+				continue
+			}
+			var dist float64
+			for ch := range 3 {
+				dist += (e[ch] - t*d[ch]) * (e[ch] - t*d[ch])
+			}
+			if dist = math.Sqrt(dist); dist < bestDist {
+				best, bestDist = lerpCMYK(a.process(), b.process(), t), dist
+			}
+		}
+	}
+
+	return best
+}
+
+func lerpCMYK(a, b color.CMYK, t float64) color.CMYK {
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.CMYK{lerp(a.C, b.C), lerp(a.M, b.M), lerp(a.Y, b.Y), lerp(a.K, b.K)}
+}
+
+// printColors reports whether the renderer paints with
+// CMYK or spot colors, in solid colors or gradients.
+func (r *QRRenderer) printColors() bool {
+	for _, i := range r.inks() {
+		if i.kind != rgbInk {
+			return true
+		}
+	}
+	for _, p := range []*gradientPaint{r.backgroundPaint, r.foregroundPaint} {
+		if p != nil && p.cmyk {
+			return true
+		}
+	}
+
+	return false
+}
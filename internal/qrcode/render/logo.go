@@ -0,0 +1,308 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	xdraw "golang.org/x/image/draw"
+)
+
+// LogoOptions controls how a logo is placed in the middle of the symbol.
+// The covered modules are only recovered through error correction, so
+// keep the logo small or use a higher error correction level.
+type LogoOptions struct {
+	// Size is the width of the logo, as a fraction of the symbol width.
+	// Logos too tall for the symbol are shrunk to fit its height.
+	Size float64
+	// Padding is the clearance around the logo, in modules.
+	Padding float64
+	// Mode either clears the message modules under the logo
+	// and its padding, or dims them.
+	Mode qrconst.LogoMode
+	// DimOpacity is the opacity of the dimmed modules.
+	DimOpacity float64
+	// Plate draws a plate behind the logo, covering the padding.
+	Plate bool
+	// PlateColor is the color of the plate. A nil color uses the background.
+	PlateColor color.Color
+	// PlateRadius is the corner radius of the plate, in modules.
+	PlateRadius float64
+	// Href references the logo from SVG output by URL,
+	// instead of embedding it as a PNG data URI.
+	Href string
+}
+
+func DefaultLogoOptions() LogoOptions {
+	return LogoOptions{
+		Size:        .2,
+		Padding:     1,
+		Mode:        qrconst.LogoClear,
+		DimOpacity:  .3,
+		Plate:       true,
+		PlateRadius: 1,
+	}
+}
+
+// WithLogo places the logo in the middle of the symbol. Finder,
+// alignment, timing and the other function patterns are never
+// covered. A nil logo removes it.
+func (r *QRRenderer) WithLogo(
+	logo image.Image,
+	opts LogoOptions,
+) *QRRenderer {
+	r.logo = logo
+	r.logoOptions = opts
+	return r
+}
+
+// logoBox is a rectangle in modules,
+// from the top-left corner of the symbol.
+type logoBox struct {
+	x0, y0, x1, y1 float64
+}
+
+func (b logoBox) intersect(o logoBox) logoBox {
+	return logoBox{
+		max(b.x0, o.x0), max(b.y0, o.y0),
+		min(b.x1, o.x1), min(b.y1, o.y1),
+	}
+}
+
+func (b logoBox) empty() bool {
+	return b.x0 >= b.x1 || b.y0 >= b.y1
+}
+
+// pixels returns the smallest pixel rectangle containing the box.
+func (b logoBox) pixels(scale, margin int) image.Rectangle {
+	s := float64(scale)
+	return image.Rect(
+		margin+int(math.Floor(b.x0*s)), margin+int(math.Floor(b.y0*s)),
+		margin+int(math.Ceil(b.x1*s)), margin+int(math.Ceil(b.y1*s)),
+	)
+}
+
+// logoLayout returns the box of the logo, the box of the area it covers
+// including the padding, and the parts of the function pattern modules
+// overlapping the area, which are kept visible.
+func (r *QRRenderer) logoLayout(qr qrcode.QRCode) (logo, area logoBox, protected []logoBox) {
+	opts := r.logoOptions
+	size := float64(qr.Size)
+	bounds := r.logo.Bounds()
+
+	w := min(1, max(0, opts.Size)) * size
+	h := w
+	if bounds.Dx() > 0 {
+		h = w * float64(bounds.Dy()) / float64(bounds.Dx())
+	}
+	// Tall logos shrink to the symbol height, keeping their aspect ratio
+	if h > size {
+		w, h = w*size/h, size
+	}
+
+	logo = logoBox{(size - w) / 2, (size - h) / 2, (size + w) / 2, (size + h) / 2}
+	padding := max(0, opts.Padding)
+	area = logoBox{
+		max(0, logo.x0-padding), max(0, logo.y0-padding),
+		min(size, logo.x1+padding), min(size, logo.y1+padding),
+	}
+
+	for y, row := range qr.Patterns {
+		for x, pattern := range row {
+			if isLogoClearable(pattern) {
+				continue
+			}
+			module := logoBox{float64(x), float64(y), float64(x + 1), float64(y + 1)}
+			if part := module.intersect(area); !part.empty() {
+				protected = append(protected, part)
+			}
+		}
+	}
+
+	return logo, area, protected
+}
+
+// isLogoClearable reports whether a module may be
+// hidden by the logo, i.e. it is not a function pattern.
+func isLogoClearable(pattern qrconst.FunctionPattern) bool {
+	return pattern.IsMessage() || pattern.IsUnoccupied()
+}
+
+// clearLogoArea returns a copy of the QR Code with
+// the message modules under the logo area turned light.
+func (r *QRRenderer) clearLogoArea(qr qrcode.QRCode) qrcode.QRCode {
+	_, area, _ := r.logoLayout(qr)
+
+	modules := make([][]bool, len(qr.Modules))
+	for y, row := range qr.Modules {
+		modules[y] = append([]bool(nil), row...)
+		for x := range row {
+			module := logoBox{float64(x), float64(y), float64(x + 1), float64(y + 1)}
+			if isLogoClearable(qr.Patterns[y][x]) && !module.intersect(area).empty() {
+				modules[y][x] = false
+			}
+		}
+	}
+
+	qr.Modules = modules
+	return qr
+}
+
+// drawLogo dims the logo area, draws the plate and scales the logo
+// into img, leaving the function pattern modules untouched.
+func (r *QRRenderer) drawLogo(img *image.RGBA, qr qrcode.QRCode, scale, margin int) {
+	opts := r.logoOptions
+	logo, area, protected := r.logoLayout(qr)
+	areaRect := area.pixels(scale, margin).Intersect(img.Bounds())
+
+	// Everything below is drawn through a mask of
+	// the area minus the function pattern modules
+	mask := image.NewAlpha(img.Bounds())
+	draw.Draw(mask, areaRect, image.Opaque, image.Point{}, draw.Src)
+	for _, part := range protected {
+		draw.Draw(mask, part.pixels(scale, margin), image.Transparent, image.Point{}, draw.Src)
+	}
+
+	bg := pixelPaint(r.backgroundColor, r.backgroundPaint, qr.Size*scale, margin)
+	plate := bg
+	if opts.PlateColor != nil {
+		plate = pixelPaint(color.NRGBAModel.Convert(opts.PlateColor).(color.NRGBA), nil, qr.Size*scale, margin)
+	}
+
+	// Coverage of the plate with its rounded corners, in pixels
+	var plateCoverage *image.Alpha
+	if opts.Plate {
+		s := float64(scale)
+		px0, py0 := float64(margin)+area.x0*s, float64(margin)+area.y0*s
+		px1, py1 := float64(margin)+area.x1*s, float64(margin)+area.y1*s
+		radius := min(max(0, opts.PlateRadius)*s, (px1-px0)/2, (py1-py0)/2)
+		plateRect := eyeRect{px0, py0, px1 - px0, py1 - py0, [4]float64{radius, radius, radius, radius}}
+		plateCoverage = eyeGeometry{fills: []eyePrimitive{plateRect}}.coverage(1, 0, 0, areaRect)
+	}
+
+	for py := areaRect.Min.Y; py < areaRect.Max.Y; py++ {
+		for px := areaRect.Min.X; px < areaRect.Max.X; px++ {
+			if mask.AlphaAt(px, py).A == 0 {
+				continue
+			}
+			c := img.RGBAAt(px, py)
+			if opts.Mode == qrconst.LogoDim {
+				c = blendRGBA(c, bg(px, py), 1-min(1, max(0, opts.DimOpacity)))
+			}
+			if plateCoverage != nil {
+				c = blendRGBA(c, plate(px, py), float64(plateCoverage.AlphaAt(px, py).A)/255)
+			}
+			img.SetRGBA(px, py, c)
+		}
+	}
+
+	xdraw.CatmullRom.Scale(
+		img, logo.pixels(scale, margin),
+		r.logo, r.logo.Bounds(),
+		draw.Over,
+		&xdraw.Options{DstMask: mask},
+	)
+}
+
+// pngDataURI encodes the image as a PNG data URI.
+func pngDataURI(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// blendRGBA composites the premultiplied color src,
+// at the given opacity, over the premultiplied color dst.
+func blendRGBA(dst, src color.RGBA, opacity float64) color.RGBA {
+	a := float64(src.A) / 255 * opacity
+	blend := func(d, s uint8) uint8 {
+		return clampUint8(float64(d)*(1-a) + float64(s)*opacity)
+	}
+
+	return color.RGBA{
+		blend(dst.R, src.R),
+		blend(dst.G, src.G),
+		blend(dst.B, src.B),
+		blend(dst.A, src.A),
+	}
+}
+
+// writeLogoSVG writes the dimming, plate and logo of the SVG output,
+// clipped to the logo area minus the function pattern modules. The
+// symbol starts at (origin, origin) in user units.
+func (r *QRRenderer) writeLogoSVG(w io.Writer, qr qrcode.QRCode, origin float64) error {
+	opts := r.logoOptions
+	logo, area, protected := r.logoLayout(qr)
+	coord := func(v float64) string {
+		return svgNum(origin + v)
+	}
+	length := svgNum
+	rect := func(b logoBox) string {
+		return fmt.Sprintf(
+			`x="%s" y="%s" width="%s" height="%s"`,
+			coord(b.x0), coord(b.y0), length(b.x1-b.x0), length(b.y1-b.y0),
+		)
+	}
+
+	href := opts.Href
+	if href == "" {
+		var err error
+		if href, err = pngDataURI(r.logo); err != nil {
+			return err
+		}
+	}
+
+	// The protected modules lie inside the area,
+	// so the even-odd rule cuts them out
+	var clip strings.Builder
+	for _, b := range append([]logoBox{area}, protected...) {
+		fmt.Fprintf(
+			&clip, "M %s %s H %s V %s H %s Z ",
+			coord(b.x0), coord(b.y0), coord(b.x1), coord(b.y1), coord(b.x0),
+		)
+	}
+
+	fmt.Fprintf(w, `	<clipPath id="%s">
+		<path d="%s" clip-rule="evenodd"/>
+	</clipPath>
+	<g clip-path="url(#%s)">
+`,
+		r.svgID("logo__clip"), strings.TrimSpace(clip.String()), r.svgID("logo__clip"),
+	)
+	if opts.Mode == qrconst.LogoDim {
+		fmt.Fprintf(
+			w, "\t\t<rect %s fill=\"%s\" opacity=\"%s\"/>\n",
+			rect(area), r.svgBackgroundFill(),
+			length(1-min(1, max(0, opts.DimOpacity))),
+		)
+	}
+	if opts.Plate {
+		fill := r.svgBackgroundFill()
+		if opts.PlateColor != nil {
+			fill = "rgba(" + rgbaComponents(color.NRGBAModel.Convert(opts.PlateColor).(color.NRGBA)) + ")"
+		}
+		radius := min(max(0, opts.PlateRadius), (area.x1-area.x0)/2, (area.y1-area.y0)/2)
+		fmt.Fprintf(
+			w, "\t\t<rect %s rx=\"%s\" fill=\"%s\"/>\n",
+			rect(area), length(radius), fill,
+		)
+	}
+	fmt.Fprintf(
+		w, "\t\t<image %s href=\"%s\" preserveAspectRatio=\"none\"/>\n\t</g>\n",
+		rect(logo), html.EscapeString(href),
+	)
+
+	return nil
+}
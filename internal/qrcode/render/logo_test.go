@@ -0,0 +1,43 @@
+package render
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+)
+
+func TestLogoLayoutAspectRatio(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := float64(qr.Size)
+
+	for _, tc := range []struct {
+		width, height int
+		size          float64
+		w, h          float64
+	}{
+		{10, 10, .2, .2 * size, .2 * size},
+		{40, 10, .5, .5 * size, .125 * size},
+		{10, 20, .4, .4 * size, .8 * size},
+		// Too tall for the symbol, shrunk to its height
+		{10, 40, .5, .25 * size, size},
+		{10, 80, 1, .125 * size, size},
+	} {
+		opts := DefaultLogoOptions()
+		opts.Size = tc.size
+		r := NewRenderer().WithLogo(image.NewRGBA(image.Rect(0, 0, tc.width, tc.height)), opts)
+
+		logo, _, _ := r.logoLayout(*qr)
+		w, h := logo.x1-logo.x0, logo.y1-logo.y0
+		if math.Abs(w-tc.w) > 1e-9 || math.Abs(h-tc.h) > 1e-9 {
+			t.Errorf("%dx%d logo of size %g: got %gx%g modules, want %gx%g", tc.width, tc.height, tc.size, w, h, tc.w, tc.h)
+		}
+		if math.Abs(logo.x0+logo.x1-size) > 1e-9 || math.Abs(logo.y0+logo.y1-size) > 1e-9 {
+			t.Errorf("%dx%d logo of size %g: got box %+v, want it centered", tc.width, tc.height, tc.size, logo)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// settingsPrefix prefixes the keywords of the
+// PNG text chunks holding the settings.
+const settingsPrefix = "qrgen:"
+
+// PNGSettings is the generation settings that PNG output records with
+// WithPNGMetadata. Gradients and pad art are not recorded, and CMYK and
+// spot colors are recorded as their RGB colors.
+type PNGSettings struct {
+	Payload    string
+	Mode       qrconst.EncodingMode
+	Version    int
+	ECLevel    qrconst.ErrorCorrectionLevel
+	MaskNum    int
+	Shape      string
+	Background color.NRGBA
+	Foreground color.NRGBA
+	Kernel     string
+	Radius     int
+}
+
+// WithPNGMetadata makes the PNG output, including the paletted and 1-bit
+// PNG output, record the payload and the generation settings in text
+// chunks, which LoadPNGSettings reads back.
+func (r *QRRenderer) WithPNGMetadata(
+	embed bool,
+) *QRRenderer {
+	r.pngMetadata = embed
+	return r
+}
+
+// pngSettings returns the settings recorded for qr.
+func (r *QRRenderer) pngSettings(qr qrcode.QRCode) PNGSettings {
+	return PNGSettings{
+		Payload:    qr.Text,
+		Mode:       qr.Mode,
+		Version:    qr.Version,
+		ECLevel:    qr.ECLevel,
+		MaskNum:    qr.MaskNum,
+		Shape:      r.shapes.get(r.moduleShape).name,
+		Background: r.backgroundColor,
+		Foreground: r.foregroundColor,
+		Kernel:     r.kernelType,
+		Radius:     r.radius,
+	}
+}
+
+// Builder returns a builder of the recorded QR Code. The version and
+// the mask are forced, so that it builds the same symbol.
+func (s *PNGSettings) Builder() *qrcode.QRBuilder {
+	maskNum := s.MaskNum
+	b := qrcode.NewQRBuilder(s.Payload).
+		WithMinVersion(s.Version).
+		WithErrorCorrectionLevel(s.ECLevel).
+		WithMaskNum(&maskNum)
+	if s.Mode != 0 {
+		b.WithEncodingMode(s.Mode)
+	}
+	return b
+}
+
+// Renderer returns a renderer with the recorded style. The shape is
+// looked up in DefaultShapeRegistry, and drawn as squares if missing.
+func (s *PNGSettings) Renderer() *QRRenderer {
+	shape, ok := DefaultShapeRegistry.Lookup(s.Shape)
+	if !ok {
+		shape = qrconst.Square
+	}
+	return NewRenderer().
+		WithModuleShape(shape).
+		WithBackgroundColor(s.Background).
+		WithForegroundColor(s.Foreground).
+		WithKernelType(s.Kernel).
+		WithRadius(s.Radius)
+}
+
+// LoadPNGSettings reads the settings recorded by WithPNGMetadata from
+// a PNG file, and returns the builder and the renderer restoring them.
+func LoadPNGSettings(rd io.Reader) (*qrcode.QRBuilder, *QRRenderer, error) {
+	s, err := ReadPNGSettings(rd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Builder(), s.Renderer(), nil
+}
+
+// ReadPNGSettings reads the settings recorded by WithPNGMetadata
+// from the text chunks of a PNG file.
+func ReadPNGSettings(rd io.Reader) (*PNGSettings, error) {
+	texts, err := readPNGText(rd)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := texts["payload"]; !ok {
+		return nil, errors.New("png: no qrgen settings")
+	}
+
+	s := &PNGSettings{
+		Payload: texts["payload"],
+		Shape:   texts["shape"],
+		Kernel:  texts["kernel"],
+	}
+	if mode := texts["mode"]; mode != "" {
+		if s.Mode, err = parseEncodingMode(mode); err != nil {
+			return nil, err
+		}
+	}
+	if s.Version, err = strconv.Atoi(texts["version"]); err != nil || s.Version < 1 || s.Version > 40 {
+		return nil, fmt.Errorf("png: invalid version %q", texts["version"])
+	}
+	var level qrconst.ErrorCorrectionLevel
+	if ec := texts["ecLevel"]; len(ec) == 1 {
+		level = qrconst.ErrorCorrectionLevel(ec[0])
+	}
+	switch level {
+	case qrconst.L, qrconst.M, qrconst.Q, qrconst.H:
+		s.ECLevel = level
+	default:
+		return nil, fmt.Errorf("png: invalid error correction level %q", texts["ecLevel"])
+	}
+	if s.MaskNum, err = strconv.Atoi(texts["mask"]); err != nil || s.MaskNum < 0 || s.MaskNum > 7 {
+		return nil, fmt.Errorf("png: invalid mask %q", texts["mask"])
+	}
+	if s.Background, err = parseHexNRGBA(texts["background"]); err != nil {
+		return nil, err
+	}
+	if s.Foreground, err = parseHexNRGBA(texts["foreground"]); err != nil {
+		return nil, err
+	}
+	if s.Radius, err = strconv.Atoi(texts["radius"]); err != nil {
+		return nil, fmt.Errorf("png: invalid radius %q", texts["radius"])
+	}
+
+	return s, nil
+}
+
+// pngWithSettings inserts text chunks recording the settings
+// after the IHDR chunk of the PNG file.
+func pngWithSettings(data []byte, s PNGSettings) []byte {
+	var chunks bytes.Buffer
+	// The payload is UTF-8, which only iTXt chunks allow
+	writePNGChunk(&chunks, "iTXt", []byte(settingsPrefix+"payload\x00\x00\x00\x00\x00"+s.Payload))
+	mode := ""
+	if s.Mode != 0 {
+		mode = s.Mode.String()
+	}
+	texts := []struct{ key, value string }{
+		{"mode", mode},
+		{"version", strconv.Itoa(s.Version)},
+		{"ecLevel", s.ECLevel.String()},
+		{"mask", strconv.Itoa(s.MaskNum)},
+		{"shape", s.Shape},
+		{"background", hexNRGBA(s.Background)},
+		{"foreground", hexNRGBA(s.Foreground)},
+		{"kernel", s.Kernel},
+		{"radius", strconv.Itoa(s.Radius)},
+	}
+	for _, t := range texts {
+		writePNGChunk(&chunks, "tEXt", []byte(settingsPrefix+t.key+"\x00"+t.value))
+	}
+
+	return insertPNGChunks(data, chunks.Bytes())
+}
+
+// insertPNGChunks inserts the chunks, as written by
+// writePNGChunk, after the IHDR chunk of the PNG file.
+func insertPNGChunks(data, chunks []byte) []byte {
+	// The signature, and the IHDR chunk with its 13 bytes of data
+	const ihdrEnd = 8 + 12 + 13
+
+	return bytes.Join([][]byte{data[:ihdrEnd], chunks, data[ihdrEnd:]}, nil)
+}
+
+func writePNGChunk(w *bytes.Buffer, chunkType string, data []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	w.Write(length[:])
+
+	start := w.Len()
+	w.WriteString(chunkType)
+	w.Write(data)
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(w.Bytes()[start:]))
+	w.Write(crc[:])
+}
+
+// readPNGText returns the text of the tEXt and iTXt chunks of a PNG
+// file, by keyword without the settings prefix, stopping at the image
+// data. Chunks of other keywords are ignored.
+func readPNGText(rd io.Reader) (map[string]string, error) {
+	br := bufio.NewReader(rd)
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(br, signature); err != nil || string(signature) != "\x89PNG\r\n\x1a\n" {
+		return nil, errors.New("png: not a PNG file")
+	}
+
+	texts := make(map[string]string)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil, fmt.Errorf("png: %w", err)
+		}
+		length, chunkType := binary.BigEndian.Uint32(header), string(header[4:])
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			return texts, nil
+		}
+		if length > 1<<24 {
+			return nil, fmt.Errorf("png: chunk %s too large", chunkType)
+		}
+
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, fmt.Errorf("png: %w", err)
+		}
+		data, crc := data[:length], binary.BigEndian.Uint32(data[length:])
+		if crc32.Update(crc32.ChecksumIEEE(header[4:]), crc32.IEEETable, data) != crc {
+			return nil, fmt.Errorf("png: chunk %s: checksum mismatch", chunkType)
+		}
+
+		var key, value string
+		var err error
+		switch chunkType {
+		case "tEXt":
+			key, value, _ = strings.Cut(string(data), "\x00")
+		case "iTXt":
+			key, value, err = parseITXt(data)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := strings.CutPrefix(key, settingsPrefix); ok {
+			texts[name] = value
+		}
+	}
+}
+
+// parseITXt returns the keyword and the text of an iTXt chunk.
+func parseITXt(data []byte) (key, text string, err error) {
+	key, rest, _ := strings.Cut(string(data), "\x00")
+	if len(rest) < 2 {
+		return "", "", errors.New("png: invalid iTXt chunk")
+	}
+	compressed := rest[0] == 1
+	// Skip the compression method, the language tag
+	// and the translated keyword
+	parts := strings.SplitN(rest[2:], "\x00", 3)
+	if len(parts) < 3 {
+		return "", "", errors.New("png: invalid iTXt chunk")
+	}
+	text = parts[2]
+	if !compressed {
+		return key, text, nil
+	}
+
+	zr, err := zlib.NewReader(strings.NewReader(text))
+	if err != nil {
+		return "", "", fmt.Errorf("png: iTXt chunk: %w", err)
+	}
+	defer zr.Close()
+	inflated, err := io.ReadAll(zr)
+	if err != nil {
+		return "", "", fmt.Errorf("png: iTXt chunk: %w", err)
+	}
+	return key, string(inflated), nil
+}
+
+// hexNRGBA formats c as #rrggbbaa.
+func hexNRGBA(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func parseHexNRGBA(s string) (color.NRGBA, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 9 || s[0] != '#' {
+		return color.NRGBA{}, fmt.Errorf("png: invalid color %q", s)
+	}
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+func parseEncodingMode(s string) (qrconst.EncodingMode, error) {
+	for _, mode := range []qrconst.EncodingMode{
+		qrconst.NumericMode,
+		qrconst.AlphanumericMode,
+		qrconst.ByteMode,
+		qrconst.KanjiMode,
+	} {
+		if mode.String() == s {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("png: invalid encoding mode %q", s)
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func metadataQRCode(t *testing.T) qrcode.QRCode {
+	t.Helper()
+
+	qr, err := qrcode.NewQRBuilder("Grüße, qrgen ✓").
+		WithErrorCorrectionLevel(qrconst.H).
+		WithMinVersion(3).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *qr
+}
+
+func metadataRenderer() *QRRenderer {
+	return NewRenderer().
+		WithModuleShape(qrconst.Circle).
+		WithBackgroundColor(color.NRGBA{250, 240, 200, 255}).
+		WithForegroundColor(color.NRGBA{20, 30, 90, 200}).
+		WithKernelType("Gaussian").
+		WithRadius(4).
+		WithModuleSize(2).
+		WithDPI(300).
+		WithPNGMetadata(true)
+}
+
+func TestPNGSettingsRoundTrip(t *testing.T) {
+	qr := metadataQRCode(t)
+	r := metadataRenderer()
+	want := PNGSettings{
+		Payload:    qr.Text,
+		Mode:       qr.Mode,
+		Version:    qr.Version,
+		ECLevel:    qrconst.H,
+		MaskNum:    qr.MaskNum,
+		Shape:      "circle",
+		Background: color.NRGBA{250, 240, 200, 255},
+		Foreground: color.NRGBA{20, 30, 90, 200},
+		Kernel:     "Gaussian",
+		Radius:     4,
+	}
+
+	for _, format := range []qrconst.RenderFormat{qrconst.RenderPNG, qrconst.RenderPalettedPNG, qrconst.RenderMonoPNG} {
+		var buf bytes.Buffer
+		if err := r.RenderToWriter(qr, &buf, format); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		// The inserted chunks keep the file valid
+		if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if !bytes.Contains(data, []byte("pHYs")) {
+			t.Errorf("format %d: the resolution is not recorded", format)
+		}
+
+		got, err := ReadPNGSettings(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if *got != want {
+			t.Errorf("format %d: got settings %+v, want %+v", format, *got, want)
+		}
+
+		builder, renderer, err := LoadPNGSettings(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.Version != qr.Version || restored.MaskNum != qr.MaskNum ||
+			!slices.EqualFunc(restored.Modules, qr.Modules, slices.Equal) {
+			t.Errorf("format %d: the restored builder builds another symbol", format)
+		}
+		if got := renderer.pngSettings(*restored); got != want {
+			t.Errorf("format %d: got restored renderer settings %+v, want %+v", format, got, want)
+		}
+	}
+}
+
+func TestPNGWithDPI(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewRenderer().WithDPI(254).RenderToWriter(metadataQRCode(t), &buf, qrconst.RenderPNG); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The pHYs chunk follows the IHDR chunk, at 10000 pixels per meter
+	const ihdrEnd = 8 + 12 + 13
+	chunk := data[ihdrEnd:]
+	if binary.BigEndian.Uint32(chunk) != 9 || string(chunk[4:8]) != "pHYs" {
+		t.Fatalf("got chunk %q after IHDR, want pHYs", chunk[4:8])
+	}
+	if x, y, unit := binary.BigEndian.Uint32(chunk[8:]), binary.BigEndian.Uint32(chunk[12:]), chunk[16]; x != 10000 || y != 10000 || unit != 1 {
+		t.Errorf("got %dx%d pixels per unit %d, want 10000x10000 per meter", x, y, unit)
+	}
+}
+
+func TestReadPNGSettingsCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	if err := metadataRenderer().RenderToWriter(metadataQRCode(t), &buf, qrconst.RenderPNG); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Change the recorded shape, without updating the checksum
+	i := bytes.Index(data, []byte(settingsPrefix+"shape\x00circle"))
+	if i < 0 {
+		t.Fatal("shape is not recorded")
+	}
+	data[i+len(settingsPrefix+"shape\x00")] = 'C'
+
+	_, err := ReadPNGSettings(bytes.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("got error %v, want a checksum mismatch", err)
+	}
+}
+
+func TestReadPNGSettingsMissing(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewRenderer().RenderToWriter(metadataQRCode(t), &buf, qrconst.RenderPNG); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPNGSettings(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("read settings from a PNG without them")
+	}
+	if _, _, err := LoadPNGSettings(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("loaded settings from a PNG without them")
+	}
+
+	buf.Reset()
+	if err := metadataRenderer().RenderToWriter(metadataQRCode(t), &buf, qrconst.RenderJPEG); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPNGSettings(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("read settings from a JPEG")
+	}
+}
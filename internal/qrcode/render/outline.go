@@ -0,0 +1,102 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Directions of the edges of the module outlines, clockwise.
+const (
+	edgeRight = iota
+	edgeDown
+	edgeLeft
+	edgeUp
+)
+
+var edgeSteps = [4][2]int{
+	edgeRight: {1, 0},
+	edgeDown:  {0, 1},
+	edgeLeft:  {-1, 0},
+	edgeUp:    {0, -1},
+}
+
+// squareOutline returns the path data of the outlines of the
+// connected modules set in cells, offset by (ox, oy) modules. Outer
+// outlines run clockwise and holes counterclockwise, so that the path
+// fills with the nonzero rule.
+func squareOutline(cells [][]bool, ox, oy int) string {
+	size := len(cells)
+	set := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < size && y < size && cells[y][x]
+	}
+
+	// The edges between set and unset modules, as a bit
+	// mask of outgoing directions for every vertex
+	stride := size + 1
+	edges := make([]uint8, stride*stride)
+	for y := range size {
+		for x := range size {
+			if !cells[y][x] {
+				continue
+			}
+			if !set(x, y-1) {
+				edges[y*stride+x] |= 1 << edgeRight
+			}
+			if !set(x+1, y) {
+				edges[y*stride+x+1] |= 1 << edgeDown
+			}
+			if !set(x, y+1) {
+				edges[(y+1)*stride+x+1] |= 1 << edgeLeft
+			}
+			if !set(x-1, y) {
+				edges[(y+1)*stride+x] |= 1 << edgeUp
+			}
+		}
+	}
+
+	var d strings.Builder
+	num := strconv.Itoa
+	for start, out := range edges {
+		if out&(1<<edgeRight) == 0 {
+			continue
+		}
+
+		x, y := start%stride, start/stride
+		d.WriteString("M" + num(ox+x) + " " + num(oy+y))
+		dir := edgeRight
+		for {
+			edges[y*stride+x] &^= 1 << dir
+			x, y = x+edgeSteps[dir][0], y+edgeSteps[dir][1]
+			out := edges[y*stride+x]
+			if out == 0 {
+				break
+			}
+
+			// Turn right first, so that modules
+			// touching by a corner stay apart
+			next := dir
+			for _, turn := range []int{1, 0, 3} {
+				if out&(1<<((dir+turn)%4)) != 0 {
+					next = (dir + turn) % 4
+					break
+				}
+			}
+			if next != dir {
+				d.WriteString(outlineRun(dir, ox+x, oy+y))
+				dir = next
+			}
+		}
+		d.WriteString("Z")
+	}
+
+	return d.String()
+}
+
+// outlineRun returns the path command of a run in
+// the direction dir, ending at the vertex (x, y).
+func outlineRun(dir, x, y int) string {
+	if dir == edgeRight || dir == edgeLeft {
+		return "H" + strconv.Itoa(x)
+	}
+	return "V" + strconv.Itoa(y)
+}
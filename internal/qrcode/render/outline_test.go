@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// svgPaint returns the color attribute of the group painting every
+// point of the SVG output, or "" for unpainted points, reading the
+// modules drawn as symbols and as outlines.
+func svgPaint(t *testing.T, svg []byte) func(p vector.Point) string {
+	t.Helper()
+
+	start, end := bytes.Index(svg, []byte("<defs>")), bytes.Index(svg, []byte("</defs>"))
+	symbols := parseSVGSymbols(t, svg[start+len("<defs>"):end])
+	var root svgNode
+	if err := xml.Unmarshal(svg, &root); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+
+	type placed struct {
+		layers []svgLayer
+		x, y   float64
+		color  string
+	}
+	// Symbols may only spill into the neighboring modules
+	var (
+		paths []placed
+		uses  = map[[2]int][]placed{}
+	)
+	for _, g := range root.Children {
+		if g.XMLName.Local != "g" {
+			continue
+		}
+		for _, n := range g.Children {
+			switch n.XMLName.Local {
+			case "use":
+				layers, ok := symbols[strings.TrimPrefix(n.attr("href"), "#")]
+				if !ok {
+					t.Fatalf("use of the missing symbol %s", n.attr("href"))
+				}
+				x, _ := strconv.ParseFloat(n.attr("x"), 64)
+				y, _ := strconv.ParseFloat(n.attr("y"), 64)
+				cell := [2]int{int(x), int(y)}
+				uses[cell] = append(uses[cell], placed{layers, x, y, g.attr("color")})
+			case "path":
+				paths = append(paths, placed{[]svgLayer{parseSVGLayer(t, n, false)}, 0, 0, g.attr("color")})
+			}
+		}
+	}
+
+	return func(p vector.Point) string {
+		shapes := slices.Clone(paths)
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				shapes = append(shapes, uses[[2]int{int(p.X) + dx, int(p.Y) + dy}]...)
+			}
+		}
+
+		paint := ""
+		for _, s := range shapes {
+			for _, layer := range s.layers {
+				if layer.contains(vector.Point{X: p.X - s.x, Y: p.Y - s.y}) {
+					paint = s.color
+					if layer.cut {
+						paint = ""
+					}
+				}
+			}
+		}
+		return paint
+	}
+}
+
+// TestCompactSVGMatchesSVG checks that merging the square modules into
+// outlines paints the same modules, in the same colors, as drawing
+// every module with its symbols, next to modules of other shapes.
+func TestCompactSVGMatchesSVG(t *testing.T) {
+	const samples = 4
+
+	qr, err := qrcode.NewQRBuilder("qrgen").
+		WithMinVersion(7).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []*QRRenderer{
+		NewRenderer(),
+		NewRenderer().WithRoleStyle(qrconst.FPAlignment, ModuleStyle{
+			Shape: qrconst.Circle,
+			Color: color.NRGBA{200, 0, 0, 255},
+		}),
+		NewRenderer().WithModuleShape(qrconst.Circle).WithDefaultFinder(true).WithRoleStyle(qrconst.FPTiming, ModuleStyle{
+			Shape: qrconst.Square,
+			Color: color.NRGBA{0, 0, 200, 255},
+		}),
+	} {
+		var full, compact bytes.Buffer
+		if err := r.WithCompactSVG(false).RenderSVG(*qr, &full); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.WithCompactSVG(true).RenderSVG(*qr, &compact); err != nil {
+			t.Fatal(err)
+		}
+		if compact.Len() >= full.Len() {
+			t.Errorf("got compact SVG of %d bytes, want less than %d", compact.Len(), full.Len())
+		}
+
+		fullPaint, compactPaint := svgPaint(t, full.Bytes()), svgPaint(t, compact.Bytes())
+		for y := range qr.Size {
+			for x := range qr.Size {
+				for s := range samples * samples {
+					p := vector.Point{
+						X: float64(r.quietZone+x) + (float64(s%samples)+.5)/samples,
+						Y: float64(r.quietZone+y) + (float64(s/samples)+.5)/samples,
+					}
+					if got, want := compactPaint(p), fullPaint(p); got != want {
+						t.Fatalf("module (%d, %d): got %q painted at %v, want %q", x, y, got, p, want)
+					}
+				}
+			}
+		}
+	}
+}
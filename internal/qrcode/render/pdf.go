@@ -0,0 +1,574 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// pointsPerMM converts millimeters to PDF points.
+const pointsPerMM = 72 / 25.4
+
+// PageSize is the size of a page, in millimeters.
+type PageSize struct {
+	Width, Height float64
+}
+
+var (
+	PageA3     = PageSize{297, 420}
+	PageA4     = PageSize{210, 297}
+	PageA5     = PageSize{148, 210}
+	PageLetter = PageSize{215.9, 279.4}
+	PageLegal  = PageSize{215.9, 355.6}
+)
+
+// PDFOptions controls the page of the PDF output, with lengths in
+// millimeters. The symbol is centered on the page.
+type PDFOptions struct {
+	// Page is the size of the page. A zero page fits the
+	// symbol, with its quiet zone and margins.
+	Page PageSize
+	// Size is the width of the symbol, quiet zone excluded. A zero
+	// size fills the page within the margins, or on a zero page, draws
+	// the modules as large as the raster output printed at its
+	// resolution.
+	Size float64
+	// Margin is the clearance between the quiet zone
+	// and the edges of the page.
+	Margin float64
+	// Title is the title of the document.
+	Title string
+}
+
+func DefaultPDFOptions() PDFOptions {
+	return PDFOptions{
+		Page:   PageA4,
+		Margin: 10,
+	}
+}
+
+// WithPDFOptions sets the page of the PDF output.
+func (r *QRRenderer) WithPDFOptions(
+	opts PDFOptions,
+) *QRRenderer {
+	r.pdfOptions = opts
+	return r
+}
+
+// pdfLayout returns the size of the page, and the width and the
+// top-left corner of the symbol with its quiet zone, in millimeters.
+func (r *QRRenderer) pdfLayout(qr qrcode.QRCode) (page PageSize, width, x, y float64, err error) {
+	opts := r.pdfOptions
+	margin := max(0, opts.Margin)
+	page = opts.Page
+	fixedPage := page.Width > 0 && page.Height > 0
+
+	width = r.printWidth(qr, opts.Size)
+	if opts.Size <= 0 && fixedPage {
+		width = min(page.Width, page.Height) - 2*margin
+	}
+	if !fixedPage {
+		page = PageSize{width + 2*margin, width + 2*margin}
+	}
+
+	if width <= 0 || width+2*margin > min(page.Width, page.Height)+1e-9 {
+		return page, 0, 0, 0, fmt.Errorf(
+			"symbol does not fit a page of %gx%gmm with %gmm margins",
+			page.Width, page.Height, margin,
+		)
+	}
+
+	return page, width, (page.Width - width) / 2, (page.Height - width) / 2, nil
+}
+
+// RenderPDF writes the QR Code as a single page PDF, with every module
+// shape drawn as vector paths. Gradients are drawn as PDF shadings,
+// which ignore the opacity of their stops. CMYK colors are painted as
+// DeviceCMYK, and spot colors as separations.
+func (r *QRRenderer) RenderPDF(qr qrcode.QRCode, w io.Writer) error {
+	page, width, x, y, err := r.pdfLayout(qr)
+	if err != nil {
+		return err
+	}
+
+	if r.logo != nil && r.logoOptions.Mode == qrconst.LogoClear {
+		qr = r.clearLogoArea(qr)
+	}
+	if r.finderStyle != nil {
+		qr = clearFinders(qr)
+	}
+
+	bw := bufio.NewWriter(w)
+	d := &pdfDocument{
+		pw:      pdf.NewWriter(bw),
+		symbols: make(map[symbolUse]pdf.Ref),
+	}
+
+	// Draw in modules, with the y axis pointing down like in SVG,
+	// and the quiet zone starting at (0, 0)
+	quietZone := r.quietZone
+	modules := float64(qr.Size + 2*quietZone)
+	unit := width * pointsPerMM / modules
+	var c pdfContent
+	c.op(
+		"%s 0 0 %s %s %s cm",
+		pdf.Num(unit), pdf.Num(-unit),
+		pdf.Num(x*pointsPerMM), pdf.Num((page.Height-y)*pointsPerMM),
+	)
+	box := fmt.Sprintf("0 0 %s %s re", pdf.Num(modules), pdf.Num(modules))
+
+	if r.backgroundPaint != nil {
+		c.op("q %s W n", box)
+		d.paintGradient(&c, r.backgroundPaint, float64(quietZone), float64(qr.Size), modules)
+		c.op("Q")
+	} else if r.backgroundColor.A > 0 {
+		c.op("q")
+		c.color(r.backgroundInk)
+		c.op("%s f Q", box)
+	}
+
+	for _, group := range r.moduleGroups(qr, false, true) {
+		if len(group.uses) == 0 && group.tiles == nil {
+			continue
+		}
+
+		// With a foreground gradient, the foreground modules are drawn in
+		// white into a soft mask, through which the gradient is painted
+		if group.key == "" && r.foregroundPaint != nil {
+			var mask pdfContent
+			mask.op("1 g 1 G")
+			d.drawModules(&mask, group, quietZone)
+			maskRef := d.pw.Alloc()
+			bbox := fmt.Sprintf("[0 0 %s %s]", pdf.Num(modules), pdf.Num(modules))
+			d.pw.Stream(maskRef, pdfGroupDict(bbox, mask.resourceDict()), mask.Bytes())
+
+			gs := c.resource("ExtGState", pdfSoftMask(maskRef))
+			c.op("q /%s gs %s W n", gs, box)
+			d.paintGradient(&c, r.foregroundPaint, float64(quietZone), float64(qr.Size), modules)
+			c.op("Q")
+			continue
+		}
+
+		paint := r.foregroundInk
+		if group.style.hasColor {
+			paint = group.style.ink
+		}
+		c.op("q")
+		c.color(paint)
+		d.drawModules(&c, group, quietZone)
+		c.op("Q")
+	}
+
+	if r.halftone != nil {
+		r.drawHalftonePDF(d, &c, qr, float64(quietZone))
+	}
+	if r.finderStyle != nil {
+		r.drawEyesPDF(d, &c, qr, float64(quietZone), modules)
+	}
+	if r.logo != nil {
+		r.drawLogoPDF(d, &c, qr, float64(quietZone), modules)
+	}
+
+	// Pages with print colors blend in CMYK, so that their
+	// inks are not converted through RGB
+	blendSpace := "/DeviceRGB"
+	if r.printColors() {
+		blendSpace = "/DeviceCMYK"
+	}
+
+	pw := d.pw
+	contents, pageRef, pages, catalog, info := pw.Alloc(), pw.Alloc(), pw.Alloc(), pw.Alloc(), pw.Alloc()
+	pw.Stream(contents, "", c.Bytes())
+	pw.Object(pageRef, fmt.Sprintf(
+		"<< /Type /Page /Parent %s /MediaBox [0 0 %s %s] /Group << /S /Transparency /CS %s >> /Resources %s /Contents %s >>",
+		pages, pdf.Num(page.Width*pointsPerMM), pdf.Num(page.Height*pointsPerMM), blendSpace, c.resourceDict(), contents,
+	))
+	pw.Object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count 1 >>", pageRef))
+	pw.Object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", pages))
+	infoDict := "<< /Producer (qrgen)"
+	if r.pdfOptions.Title != "" {
+		infoDict += " /Title " + pdf.Text(r.pdfOptions.Title)
+	}
+	pw.Object(info, infoDict+" >>")
+
+	if err := pw.Close(catalog, info); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// pdfDocument holds the objects shared by the content streams of a PDF.
+type pdfDocument struct {
+	pw *pdf.Writer
+	// symbols holds the forms of the symbols, keyed by their
+	// use at (0, 0)
+	symbols map[symbolUse]pdf.Ref
+}
+
+// drawModules draws the symbols and the merged tiles of the group
+// with the current color, offset by the quiet zone.
+func (d *pdfDocument) drawModules(c *pdfContent, group *moduleGroup, quietZone int) {
+	for _, use := range group.uses {
+		name := c.resource("XObject", d.symbolForm(use.def, use.name).String())
+		c.op("q 1 0 0 1 %d %d cm /%s Do Q", quietZone+use.x, quietZone+use.y, name)
+	}
+	if group.tiles != nil {
+		c.path(vector.MustParsePath(squareOutline(group.tiles, quietZone, quietZone)))
+		c.op("f")
+	}
+}
+
+// symbolForm returns the form drawing a symbol of the shape in the
+// current color. Like in SVG, cut layers are drawn in black into a
+// luminosity soft mask, through which a rectangle is painted.
+func (d *pdfDocument) symbolForm(def *shapeDef, name string) pdf.Ref {
+	key := symbolUse{def: def, name: name}
+	if ref, ok := d.symbols[key]; ok {
+		return ref
+	}
+
+	layers := def.symbols[name]
+	x0, y0, x1, y1 := symbolBounds(layers)
+	bbox := fmt.Sprintf("[%s %s %s %s]", pdf.Num(x0), pdf.Num(y0), pdf.Num(x1), pdf.Num(y1))
+
+	hasCut := slices.ContainsFunc(layers, func(layer tables.ShapeLayer) bool {
+		return layer.Cut
+	})
+
+	var c pdfContent
+	if !hasCut {
+		for _, layer := range layers {
+			c.layer(layer)
+		}
+	} else {
+		var mask pdfContent
+		for _, layer := range layers {
+			gray := "1"
+			if layer.Cut {
+				gray = "0"
+			}
+			mask.op("%s g %s G", gray, gray)
+			mask.layer(layer)
+		}
+		maskRef := d.pw.Alloc()
+		d.pw.Stream(maskRef, pdfGroupDict(bbox, mask.resourceDict()), mask.Bytes())
+
+		gs := c.resource("ExtGState", pdfSoftMask(maskRef))
+		c.op(
+			"/%s gs %s %s %s %s re f",
+			gs, pdf.Num(x0), pdf.Num(y0), pdf.Num(x1-x0), pdf.Num(y1-y0),
+		)
+	}
+
+	ref := d.pw.Alloc()
+	d.pw.Stream(ref, "/Type /XObject /Subtype /Form /BBox "+bbox+" /Resources "+c.resourceDict(), c.Bytes())
+	d.symbols[key] = ref
+	return ref
+}
+
+// pdfGroupDict returns the dictionary entries of a form drawing
+// a luminosity soft mask.
+func pdfGroupDict(bbox, resources string) string {
+	return "/Type /XObject /Subtype /Form /BBox " + bbox +
+		" /Group << /S /Transparency /CS /DeviceGray >> /Resources " + resources
+}
+
+// pdfSoftMask returns the graphics state masking
+// by the luminosity of the group form.
+func pdfSoftMask(group pdf.Ref) string {
+	return "<< /Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G " + group.String() + " >> >>"
+}
+
+// image returns the image XObject of img,
+// with a soft mask for its transparency.
+func (d *pdfDocument) image(img image.Image) pdf.Ref {
+	b := img.Bounds()
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 255
+		}
+	}
+
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	smask := ""
+	if !opaque {
+		ref := d.pw.Alloc()
+		d.pw.Stream(ref, dict+" /ColorSpace /DeviceGray", alpha)
+		smask = " /SMask " + ref.String()
+	}
+
+	ref := d.pw.Alloc()
+	d.pw.Stream(ref, dict+" /ColorSpace /DeviceRGB"+smask, rgb)
+	return ref
+}
+
+// drawImage draws img scaled to the rectangle at (x, y) in user units.
+func (d *pdfDocument) drawImage(c *pdfContent, img image.Image, x, y, w, h float64) {
+	name := c.resource("XObject", d.image(img).String())
+	// Images span the unit square upwards, and the y axis points down
+	c.op(
+		"q %s 0 0 %s %s %s cm /%s Do Q",
+		pdf.Num(w), pdf.Num(-h), pdf.Num(x), pdf.Num(y+h), name,
+	)
+}
+
+// paintGradient paints the gradient over the current clipping path.
+// The symbol spans size user units from (origin, origin), and the
+// page area to cover spans extent user units from (0, 0). Conic
+// gradients are painted as solid wedges, like in SVG.
+func (d *pdfDocument) paintGradient(c *pdfContent, p *gradientPaint, origin, size, extent float64) {
+	if len(p.colors) == 0 {
+		return
+	}
+	if p.Type == qrconst.ConicGradient {
+		for _, wedge := range gradientWedges(p, origin, size, extent) {
+			c.color(wedge.ink)
+			c.op("%s f", wedge.pdfPath())
+		}
+		return
+	}
+	if p.Type == qrconst.RadialGradient && p.Radius <= 0 {
+		c.op("q")
+		c.color(p.inkAt(1))
+		c.op("0 0 %s %s re f Q", pdf.Num(extent), pdf.Num(extent))
+		return
+	}
+
+	// Gradients with print colors are interpolated in CMYK
+	colorSpace, components := "/DeviceRGB", func(i ink) string {
+		return pdfRGB(i.rgb)
+	}
+	if p.cmyk {
+		colorSpace, components = "/DeviceCMYK", func(i ink) string {
+			return cmykComponents(i.process())
+		}
+	}
+	shadingType, coords := shadingCoords(p, origin, size)
+	name := c.resource("Shading", fmt.Sprintf(
+		"<< /ShadingType %d /Coords [%s] /ColorSpace %s /Function %s /Extend [true true] >>",
+		shadingType, coords, colorSpace, gradientFunction(p, components),
+	))
+	c.op("/%s sh", name)
+}
+
+// drawEyesPDF draws the frames and the balls of the finder patterns.
+// The symbol starts at (origin, origin) in user units.
+func (r *QRRenderer) drawEyesPDF(d *pdfDocument, c *pdfContent, qr qrcode.QRCode, origin, extent float64) {
+	style := r.finderStyle
+	fill := func(path string, evenOdd bool, col color.Color) {
+		paint, clip := "f", "W n"
+		if evenOdd {
+			paint, clip = "f*", "W* n"
+		}
+
+		c.op("q")
+		c.path(vector.MustParsePath(path))
+		switch {
+		case col != nil:
+			c.color(newInk(col))
+			c.op("%s", paint)
+		case r.foregroundPaint != nil:
+			c.op("%s", clip)
+			d.paintGradient(c, r.foregroundPaint, origin, float64(qr.Size), extent)
+		default:
+			c.color(r.foregroundInk)
+			c.op("%s", paint)
+		}
+		c.op("Q")
+	}
+
+	for _, eye := range finderEyes(qr) {
+		ox, oy := origin+float64(eye.x), origin+float64(eye.y)
+
+		if style.Frame == qrconst.EyeImage && style.FrameImage != nil {
+			d.drawImage(c, style.FrameImage, ox, oy, 7, 7)
+		} else {
+			fill(newEyeGeometry(style.Frame, false, eye.mirrorX, eye.mirrorY).path(ox, oy), true, style.FrameColor)
+		}
+
+		if style.Ball == qrconst.EyeImage && style.BallImage != nil {
+			d.drawImage(c, style.BallImage, ox+2, oy+2, 3, 3)
+		} else {
+			fill(newEyeGeometry(style.Ball, true, eye.mirrorX, eye.mirrorY).path(ox, oy), false, style.BallColor)
+		}
+	}
+}
+
+// drawLogoPDF draws the dimming, plate and logo, clipped to the logo
+// area minus the function pattern modules. The symbol starts at
+// (origin, origin) in user units.
+func (r *QRRenderer) drawLogoPDF(d *pdfDocument, c *pdfContent, qr qrcode.QRCode, origin, extent float64) {
+	opts := r.logoOptions
+	logo, area, protected := r.logoLayout(qr)
+	rect := func(b logoBox) string {
+		return fmt.Sprintf(
+			"%s %s %s %s re",
+			pdf.Num(origin+b.x0), pdf.Num(origin+b.y0), pdf.Num(b.x1-b.x0), pdf.Num(b.y1-b.y0),
+		)
+	}
+	background := func(opacity float64) {
+		if r.backgroundPaint != nil {
+			c.alpha(opacity)
+			d.paintGradient(c, r.backgroundPaint, origin, float64(qr.Size), extent)
+			return
+		}
+		c.ink(r.backgroundInk)
+		c.alpha(opacity * float64(r.backgroundColor.A) / 255)
+		c.op("0 0 %s %s re f", pdf.Num(extent), pdf.Num(extent))
+	}
+
+	// The protected modules lie inside the area,
+	// so the even-odd rule cuts them out
+	c.op("q")
+	for _, b := range append([]logoBox{area}, protected...) {
+		c.op("%s", rect(b))
+	}
+	c.op("W* n")
+
+	if opts.Mode == qrconst.LogoDim {
+		c.op("q %s W n", rect(area))
+		background(1 - min(1, max(0, opts.DimOpacity)))
+		c.op("Q")
+	}
+	if opts.Plate {
+		radius := min(max(0, opts.PlateRadius), (area.x1-area.x0)/2, (area.y1-area.y0)/2)
+		plate := eyeRect{area.x0, area.y0, area.x1 - area.x0, area.y1 - area.y0, [4]float64{radius, radius, radius, radius}}
+		c.op("q")
+		c.path(vector.MustParsePath(plate.path(origin, origin)))
+		if opts.PlateColor != nil {
+			c.color(newInk(opts.PlateColor))
+			c.op("f")
+		} else {
+			c.op("W n")
+			background(1)
+		}
+		c.op("Q")
+	}
+	d.drawImage(c, r.logo, origin+logo.x0, origin+logo.y0, logo.x1-logo.x0, logo.y1-logo.y0)
+	c.op("Q")
+}
+
+// pdfContent is a content stream, with the resources it uses.
+type pdfContent struct {
+	vectorContent
+	// names maps the objects to their names, and resources the
+	// names to the objects, by category, e.g. "XObject"
+	names     map[string]map[string]string
+	resources map[string]map[string]string
+}
+
+// resource returns the name of the object, given in PDF syntax,
+// among the resources of the category.
+func (c *pdfContent) resource(category, object string) string {
+	if c.names == nil {
+		c.names = make(map[string]map[string]string)
+		c.resources = make(map[string]map[string]string)
+	}
+	if c.names[category] == nil {
+		c.names[category] = make(map[string]string)
+		c.resources[category] = make(map[string]string)
+	}
+	if name, ok := c.names[category][object]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("%s%d", category[:1], len(c.names[category]))
+	c.names[category][object] = name
+	c.resources[category][name] = object
+	return name
+}
+
+// resourceDict returns the resource dictionary of the content.
+func (c *pdfContent) resourceDict() string {
+	var b strings.Builder
+	b.WriteString("<<")
+	for _, category := range slices.Sorted(maps.Keys(c.resources)) {
+		objects := c.resources[category]
+		b.WriteString(" /" + category + " <<")
+		for _, name := range slices.Sorted(maps.Keys(objects)) {
+			b.WriteString(" /" + name + " " + objects[name])
+		}
+		b.WriteString(" >>")
+	}
+	b.WriteString(" >>")
+
+	return b.String()
+}
+
+// color sets the fill and stroke colors, and their opacity.
+func (c *pdfContent) color(i ink) {
+	c.ink(i)
+	if i.rgb.A < 255 {
+		c.alpha(float64(i.rgb.A) / 255)
+	}
+}
+
+// ink sets the fill and stroke colors, ignoring their opacity. Spot
+// colors are painted in a separation color space, falling back to
+// their CMYK color on devices without the ink.
+func (c *pdfContent) ink(i ink) {
+	switch i.kind {
+	case cmykInk:
+		cmyk := cmykComponents(i.cmyk)
+		c.op("%s k %s K", cmyk, cmyk)
+	case spotInk:
+		cs := c.resource("ColorSpace", fmt.Sprintf(
+			"[/Separation %s /DeviceCMYK << /FunctionType 2 /Domain [0 1] /C0 [0 0 0 0] /C1 [%s] /N 1 >>]",
+			pdf.Name(i.name), cmykComponents(i.cmyk),
+		))
+		tint := pdf.Num(i.tint)
+		c.op("/%s cs /%s CS %s scn %s SCN", cs, cs, tint, tint)
+	default:
+		rgb := pdfRGB(i.rgb)
+		c.op("%s rg %s RG", rgb, rgb)
+	}
+}
+
+// pdfRGB returns the components of the color, ignoring its opacity.
+func pdfRGB(c color.NRGBA) string {
+	return pdf.Num(float64(c.R)/255) + " " + pdf.Num(float64(c.G)/255) + " " + pdf.Num(float64(c.B)/255)
+}
+
+// alpha sets the opacity of the fills and strokes.
+func (c *pdfContent) alpha(a float64) {
+	gs := c.resource("ExtGState", fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", pdf.Num(a), pdf.Num(a)))
+	c.op("/%s gs", gs)
+}
+
+// layer draws the shape layer with the current color. The line cap
+// and join values of qrconst match the PDF ones.
+func (c *pdfContent) layer(layer tables.ShapeLayer) {
+	stroke := layer.StrokeWidth > 0
+	if stroke {
+		c.op("%s w %d J %d j 4 M", pdf.Num(layer.StrokeWidth), layer.LineCap, layer.LineJoin)
+	}
+	c.path(vector.MustParsePath(layer.Path))
+
+	switch {
+	case layer.Fill && stroke:
+		c.op("B")
+	case layer.Fill:
+		c.op("f")
+	case stroke:
+		c.op("S")
+	default:
+		c.op("n")
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+var (
+	pdfTrailerPattern  = regexp.MustCompile(`trailer\n<< /Size (\d+) [^\n]*>>\nstartxref\n(\d+)\n%%EOF\n$`)
+	pdfObjectPattern   = regexp.MustCompile(`(?m)^(\d+) 0 obj$`)
+	pdfMediaBoxPattern = regexp.MustCompile(`/MediaBox \[0 0 (\S+) (\S+)\]`)
+)
+
+// checkPDFStructure checks that the cross reference table of the PDF
+// points at every object, and returns the page size in points.
+func checkPDFStructure(t *testing.T, data []byte) (width, height string) {
+	t.Helper()
+
+	m := pdfTrailerPattern.FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no trailer")
+	}
+	size, _ := strconv.Atoi(string(m[1]))
+	xref, _ := strconv.Atoi(string(m[2]))
+	header := fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", size)
+	if xref >= len(data) || !bytes.HasPrefix(data[xref:], []byte(header)) {
+		t.Fatalf("startxref %d does not point at a table of %d entries", xref, size)
+	}
+
+	objects := pdfObjectPattern.FindAllSubmatch(data, -1)
+	if len(objects) != size-1 {
+		t.Errorf("got /Size %d, want %d for %d objects", size, len(objects)+1, len(objects))
+	}
+	entries := data[xref+len(header):]
+	for i := 1; i < size; i++ {
+		entry := string(entries[20*(i-1) : 20*i])
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil || entry[10:] != " 00000 n \n" {
+			t.Fatalf("got cross reference entry %q of object %d", entry, i)
+		}
+		if !bytes.HasPrefix(data[offset:], fmt.Appendf(nil, "%d 0 obj\n", i)) {
+			t.Errorf("entry of object %d points at %q", i, data[offset:min(len(data), offset+12)])
+		}
+	}
+
+	boxes := pdfMediaBoxPattern.FindAllSubmatch(data, -1)
+	if len(boxes) != 1 {
+		t.Fatalf("got %d pages, want 1", len(boxes))
+	}
+	return string(boxes[0][1]), string(boxes[0][2])
+}
+
+func TestRenderPDF(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("https://example.com/qrgen").
+		WithErrorCorrectionLevel(qrconst.H).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range logo.Pix {
+		logo.Pix[i] = uint8(i)
+	}
+	stops := []GradientStop{
+		{0, color.NRGBA{200, 0, 0, 255}},
+		{1, color.NRGBA{0, 0, 200, 255}},
+	}
+	spot := SpotColor{Name: "PANTONE 185 C", Tint: .8, CMYK: color.CMYK{0, 230, 200, 0}}
+	// Size of the fitted pages, for a symbol of 50mm
+	fitted := 50*float64(qr.Size+8)/float64(qr.Size) + 2*5
+
+	for _, tc := range []struct {
+		name   string
+		r      *QRRenderer
+		page   PageSize
+		expect []string
+	}{
+		{
+			"gradient",
+			NewRenderer().WithForegroundGradient(NewLinearGradient(45, stops...)),
+			PageA4,
+			[]string{"/ShadingType 2"},
+		},
+		{
+			"smiley",
+			NewRenderer().
+				WithModuleShape(qrconst.SmileyFace).
+				WithPDFOptions(PDFOptions{Size: 50, Margin: 5, Title: "Smiley é"}),
+			PageSize{fitted, fitted},
+			[]string{"/Title <FEFF"},
+		},
+		{
+			"spot",
+			NewRenderer().
+				WithForegroundColor(spot).
+				WithPDFOptions(PDFOptions{Page: PageLetter, Size: 100}),
+			PageLetter,
+			[]string{"/Separation /PANTONE#20185#20C"},
+		},
+		{
+			"logo",
+			NewRenderer().
+				WithBackgroundGradient(NewConicGradient(.5, .5, 0, stops...)).
+				WithLogo(logo, DefaultLogoOptions()).
+				WithPDFOptions(PDFOptions{Page: PageA5, Margin: 0}),
+			PageA5,
+			[]string{"/Subtype /Image /Width 8 /Height 8", "/SMask"},
+		},
+	} {
+		var b bytes.Buffer
+		if err := tc.r.RenderPDF(*qr, &b); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		width, height := checkPDFStructure(t, b.Bytes())
+		if want := pdf.Num(tc.page.Width * pointsPerMM); width != want {
+			t.Errorf("%s: got page width %s, want %s", tc.name, width, want)
+		}
+		if want := pdf.Num(tc.page.Height * pointsPerMM); height != want {
+			t.Errorf("%s: got page height %s, want %s", tc.name, height, want)
+		}
+		for _, s := range tc.expect {
+			if !bytes.Contains(b.Bytes(), []byte(s)) {
+				t.Errorf("%s: missing %q", tc.name, s)
+			}
+		}
+	}
+}
+
+func TestRenderPDFDoesNotFit(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []PDFOptions{
+		{Page: PageA5, Size: 200},
+		{Page: PageA4, Size: 180, Margin: 20},
+		{Page: PageA4, Margin: 105},
+		{Page: PageSize{50, 80}, Size: 40, Margin: 10},
+	} {
+		var b bytes.Buffer
+		err := NewRenderer().WithPDFOptions(opts).RenderPDF(*qr, &b)
+		if err == nil || !strings.Contains(err.Error(), "does not fit") {
+			t.Errorf("%+v: got error %v, want the symbol not fitting", opts, err)
+		}
+		if b.Len() > 0 {
+			t.Errorf("%+v: wrote %d bytes", opts, b.Len())
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// Inch is the length of an inch in millimeters, for physical sizes
+// given in inches, e.g. 1.5 * Inch.
+const Inch = 25.4
+
+// screenDPI is the resolution assumed for raster
+// output without a resolution or a physical size.
+const screenDPI = 96
+
+// WithDPI sets the resolution of the raster output, in device pixels per
+// inch, which PNG, JPEG, TIFF and BMP files record so that they print at
+// their physical size. A resolution of 0 disables the metadata.
+func (r *QRRenderer) WithDPI(
+	dpi float64,
+) *QRRenderer {
+	r.dpi = max(0, dpi)
+	return r
+}
+
+// WithPhysicalSize sets the printed width of the symbol in millimeters,
+// quiet zone excluded, at the resolution set by WithDPI (96 DPI unless
+// set), overriding the module and output sizes of the raster output.
+// Modules keep a whole number of device pixels, so the printed width is
+// rounded to the nearest module size in pixels. A size of 0 disables it.
+func (r *QRRenderer) WithPhysicalSize(
+	size float64,
+) *QRRenderer {
+	r.physicalSize = max(0, size)
+	return r
+}
+
+// MinModuleSize returns the recommended minimum module size in
+// millimeters for a QR Code scanned from the given distance in
+// millimeters. It follows the rule of thumb of a symbol one tenth of
+// the scanning distance wide for 25 modules, and stays above the
+// smallest modules printers reproduce reliably.
+func MinModuleSize(distance float64) float64 {
+	const minPrinted = 0.25
+	return max(minPrinted, distance/250)
+}
+
+// rasterDPI returns the resolution of the raster output.
+func (r *QRRenderer) rasterDPI() float64 {
+	if r.dpi > 0 {
+		return r.dpi
+	}
+	return screenDPI
+}
+
+// metadataDPI returns the resolution recorded in the raster output,
+// zero when neither a resolution nor a physical size is set.
+func (r *QRRenderer) metadataDPI() float64 {
+	if r.dpi > 0 || r.physicalSize > 0 {
+		return r.rasterDPI()
+	}
+	return 0
+}
+
+// physicalScale returns the number of device pixels per module
+// printing the symbol at its physical size.
+func (r *QRRenderer) physicalScale(qr qrcode.QRCode) int {
+	pixels := r.physicalSize / Inch * r.rasterDPI()
+	return max(1, int(math.Round(pixels/float64(qr.Size))))
+}
+
+// withDPI records the resolution in the encoded raster output. GIF files
+// have no resolution, and CMYK JPEG files are written with theirs.
+func withDPI(format qrconst.RenderFormat, data []byte, dpi float64) []byte {
+	switch {
+	case isPNG(format):
+		return pngWithDPI(data, dpi)
+	case format == qrconst.RenderJPEG:
+		return jpegWithDPI(data, dpi)
+	case format == qrconst.RenderTIFF:
+		return tiffWithDPI(data, dpi)
+	case format == qrconst.RenderBMP:
+		return bmpWithDPI(data, dpi)
+	}
+	return data
+}
+
+// pixelsPerMeter converts a resolution in DPI.
+func pixelsPerMeter(dpi float64) uint32 {
+	return uint32(math.Round(dpi * 1000 / Inch))
+}
+
+// pngWithDPI inserts a pHYs chunk recording the resolution
+// after the IHDR chunk of the PNG file.
+func pngWithDPI(data []byte, dpi float64) []byte {
+	phys := make([]byte, 9)
+	ppm := pixelsPerMeter(dpi)
+	binary.BigEndian.PutUint32(phys, ppm)
+	binary.BigEndian.PutUint32(phys[4:], ppm)
+	// The unit is the meter
+	phys[8] = 1
+
+	var chunk bytes.Buffer
+	writePNGChunk(&chunk, "pHYs", phys)
+	return insertPNGChunks(data, chunk.Bytes())
+}
+
+// jpegWithDPI inserts a JFIF APP0 segment recording the resolution
+// after the SOI marker of the JPEG file.
+func jpegWithDPI(data []byte, dpi float64) []byte {
+	density := uint16(min(math.MaxUint16, math.Round(dpi)))
+	app0 := []byte{
+		0xff, 0xe0, 0, 16,
+		'J', 'F', 'I', 'F', 0,
+		1, 2, // version 1.02
+		1, // the unit is the inch
+		byte(density >> 8), byte(density), byte(density >> 8), byte(density),
+		0, 0, // no thumbnail
+	}
+
+	return bytes.Join([][]byte{data[:2], app0, data[2:]}, nil)
+}
+
+// tiffWithDPI replaces the resolution of the TIFF file, which
+// golang.org/x/image/tiff always writes as 72 DPI.
+func tiffWithDPI(data []byte, dpi float64) []byte {
+	const (
+		xResolution = 282
+		yResolution = 283
+	)
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+	ifd := order.Uint32(data[4:])
+	entries := int(order.Uint16(data[ifd:]))
+	for i := range entries {
+		entry := data[int(ifd)+2+12*i:]
+		if tag := order.Uint16(entry); tag == xResolution || tag == yResolution {
+			// The rational value is stored at the offset, as two
+			// 32-bit integers, with the resolution in hundredths
+			value := order.Uint32(entry[8:])
+			order.PutUint32(data[value:], uint32(math.Round(dpi*100)))
+			order.PutUint32(data[value+4:], 100)
+		}
+	}
+
+	return data
+}
+
+// bmpWithDPI sets the resolution in the info header of the BMP file.
+func bmpWithDPI(data []byte, dpi float64) []byte {
+	// The file header, and the info header fields before the resolution
+	const resolution = 14 + 24
+
+	ppm := pixelsPerMeter(dpi)
+	binary.LittleEndian.PutUint32(data[resolution:], ppm)
+	binary.LittleEndian.PutUint32(data[resolution+4:], ppm)
+	return data
+}
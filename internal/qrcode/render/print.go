@@ -0,0 +1,146 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/pdf"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// printWidth returns the width in millimeters of the symbol with its
+// quiet zone, for a symbol of size millimeters without its quiet zone.
+// A zero size draws the modules as large as the raster output printed
+// at its resolution, 96 DPI unless set.
+func (r *QRRenderer) printWidth(qr qrcode.QRCode, size float64) float64 {
+	modules := float64(qr.Size + 2*r.quietZone)
+	if size > 0 {
+		return size * modules / float64(qr.Size)
+	}
+
+	scale, _, _ := r.layout(qr)
+	return float64(scale) * modules * Inch / r.rasterDPI()
+}
+
+// vectorContent is the drawing operators of a PDF or PostScript output.
+type vectorContent struct {
+	bytes.Buffer
+}
+
+// op writes an operation, given as a format and its arguments.
+func (c *vectorContent) op(format string, args ...any) {
+	fmt.Fprintf(c, format, args...)
+	c.WriteByte('\n')
+}
+
+// path writes the path construction operators of p, in the PDF
+// syntax, which the prolog of the PostScript output defines too.
+func (c *vectorContent) path(p vector.Path) {
+	num := pdf.Num
+	var current, start vector.Point
+	for _, seg := range p {
+		pts := seg.Points
+		switch seg.Op {
+		case vector.OpMoveTo:
+			c.op("%s %s m", num(pts[0].X), num(pts[0].Y))
+			current, start = pts[0], pts[0]
+		case vector.OpLineTo:
+			c.op("%s %s l", num(pts[0].X), num(pts[0].Y))
+			current = pts[0]
+		case vector.OpQuadTo:
+			// Quadratic curves are raised to cubic ones
+			q, end := pts[0], pts[1]
+			c.op(
+				"%s %s %s %s %s %s c",
+				num(current.X+2*(q.X-current.X)/3), num(current.Y+2*(q.Y-current.Y)/3),
+				num(end.X+2*(q.X-end.X)/3), num(end.Y+2*(q.Y-end.Y)/3),
+				num(end.X), num(end.Y),
+			)
+			current = end
+		case vector.OpCubeTo:
+			c.op(
+				"%s %s %s %s %s %s c",
+				num(pts[0].X), num(pts[0].Y), num(pts[1].X), num(pts[1].Y), num(pts[2].X), num(pts[2].Y),
+			)
+			current = pts[2]
+		case vector.OpClose:
+			c.op("h")
+			current = start
+		}
+	}
+}
+
+// gradientFunction returns the PDF and PostScript function interpolating
+// the inks of the stops, written as components by the components
+// function, over the gradient positions from 0 to 1.
+func gradientFunction(p *gradientPaint, components func(ink) string) string {
+	offsets, colors := p.offsets, p.inks
+	// Positions outside the stops take the color of the nearest stop
+	if offsets[0] > 0 {
+		offsets = append([]float64{0}, offsets...)
+		colors = append([]ink{colors[0]}, colors...)
+	}
+	if offsets[len(offsets)-1] < 1 {
+		offsets = append(slices.Clone(offsets), 1)
+		colors = append(slices.Clone(colors), colors[len(colors)-1])
+	}
+
+	interpolate := func(a, b ink) string {
+		return "<< /FunctionType 2 /Domain [0 1] /C0 [" + components(a) + "] /C1 [" + components(b) + "] /N 1 >>"
+	}
+
+	// Stops at the same offset make a hard transition,
+	// and their empty interval is left out
+	var functions, bounds, encode []string
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] <= offsets[i-1] {
+			continue
+		}
+		if len(functions) > 0 {
+			bounds = append(bounds, pdf.Num(offsets[i-1]))
+		}
+		functions = append(functions, interpolate(colors[i-1], colors[i]))
+		encode = append(encode, "0 1")
+	}
+
+	switch len(functions) {
+	case 0:
+		last := colors[len(colors)-1]
+		return interpolate(last, last)
+	case 1:
+		return functions[0]
+	}
+	return fmt.Sprintf(
+		"<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "),
+	)
+}
+
+// shadingCoords returns the shading type and the coordinates of a linear
+// or radial gradient, for PDF and PostScript shadings. The symbol spans
+// size user units from (origin, origin).
+func shadingCoords(p *gradientPaint, origin, size float64) (shadingType int, coords string) {
+	num := pdf.Num
+	if p.Type == qrconst.RadialGradient {
+		cx, cy := origin+p.CenterX*size, origin+p.CenterY*size
+		return 3, fmt.Sprintf("%s %s 0 %s %s %s", num(cx), num(cy), num(cx), num(cy), num(p.Radius*size))
+	}
+
+	// Linear gradients always run through the center of the symbol
+	c := origin + size/2
+	dx, dy, length := p.linearDirection()
+	half := length * size / 2
+	return 2, fmt.Sprintf("%s %s %s %s", num(c-dx*half), num(c-dy*half), num(c+dx*half), num(c+dy*half))
+}
+
+// pdfPath returns the path of the wedge in PDF syntax,
+// which the EPS prolog shares.
+func (w gradientWedge) pdfPath() string {
+	num := pdf.Num
+	a, b, c := w.points[0], w.points[1], w.points[2]
+	return fmt.Sprintf("%s %s m %s %s l %s %s l h", num(a.X), num(a.Y), num(b.X), num(b.Y), num(c.X), num(c.Y))
+}
@@ -0,0 +1,263 @@
+package render
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// rasterTraceScale is the number of pixels per module at which the
+// symbols of a shape defined only by its Raster function are traced
+// for SVG outputs.
+const rasterTraceScale = 16
+
+// Shape is a module shape defined by an application. Every module is
+// drawn with a few symbols, picked from its neighbors by Render for a
+// dark module and by Merge for a light module.
+type Shape struct {
+	// Name identifies the shape, e.g. in CLI flags and SVG ids. It is
+	// made of ASCII letters and digits, and starts with a letter.
+	Name string
+	// Symbols holds the SVG path data of every symbol, in module units
+	// with the module spanning from (0, 0) to (1, 1), filled with the
+	// nonzero rule. Symbols may extend past the module to connect with
+	// their neighbors. The path data may be empty if Raster is set.
+	Symbols map[string]string
+	// Raster optionally reports whether the pixel (px, py) of a symbol
+	// is painted, for modules of scale pixels, with px and py within
+	// [0, scale). When set, raster outputs use it instead of the path
+	// data, and symbols without path data are traced from it for SVG.
+	Raster func(symbol string, px, py, scale int) bool
+	// Render returns the symbols drawn for a dark module.
+	Render func(lookahead qrconst.Lookahead) []string
+	// Merge optionally returns the symbols drawn for a light module,
+	// e.g. to bridge its dark neighbors.
+	Merge func(lookahead qrconst.Lookahead) []string
+}
+
+// shapeDef is a registered shape, with its symbols as layers.
+type shapeDef struct {
+	name    string
+	symbols map[string][]tables.ShapeLayer
+	raster  func(symbol string, px, py, scale int) bool
+	render  func(lookahead qrconst.Lookahead) []string
+	merge   func(lookahead qrconst.Lookahead) []string
+	// tile is set for shapes whose dark modules fill exactly their
+	// square, and can be merged with their neighbors into outlines
+	tile bool
+}
+
+// moduleSymbols returns the names of the symbols drawn for a dark
+// module, or for a light module merging its dark neighbors.
+func (d *shapeDef) moduleSymbols(dark bool, lookahead qrconst.Lookahead) []string {
+	if dark {
+		return d.render(lookahead)
+	}
+	if d.merge != nil {
+		return d.merge(lookahead)
+	}
+	return nil
+}
+
+// ShapeRegistry holds the module shapes available to renderers, the
+// built-in ones and those registered by the application. It is safe
+// for concurrent use.
+type ShapeRegistry struct {
+	mu     sync.RWMutex
+	shapes []*shapeDef
+	names  map[string]qrconst.ModuleShape
+}
+
+// DefaultShapeRegistry is the registry used by renderers
+// unless WithShapeRegistry is set.
+var DefaultShapeRegistry = NewShapeRegistry()
+
+// NewShapeRegistry returns a registry of the built-in shapes.
+func NewShapeRegistry() *ShapeRegistry {
+	sr := &ShapeRegistry{
+		names: make(map[string]qrconst.ModuleShape),
+	}
+	for shape := qrconst.Square; shape <= qrconst.Pointillism; shape++ {
+		sr.shapes = append(sr.shapes, &shapeDef{
+			name:    shape.String(),
+			symbols: tables.ShapeSymbols[shape],
+			render:  tables.ShapeRenderFunctions[shape],
+			merge:   tables.ShapeMergeFunctions[shape],
+			tile:    shape == qrconst.Square,
+		})
+		sr.names[shape.String()] = shape
+	}
+
+	return sr
+}
+
+// Register adds the shape to the registry, and returns the module
+// shape to pass to the renderers using this registry, e.g. to
+// WithModuleShape or in a ModuleStyle.
+func (sr *ShapeRegistry) Register(shape Shape) (qrconst.ModuleShape, error) {
+	def, err := newShapeDef(shape)
+	if err != nil {
+		return 0, err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if _, ok := sr.names[shape.Name]; ok {
+		return 0, fmt.Errorf("shape %q is already registered", shape.Name)
+	}
+	id := qrconst.ModuleShape(len(sr.shapes))
+	sr.shapes = append(sr.shapes, def)
+	sr.names[shape.Name] = id
+
+	return id, nil
+}
+
+// Lookup returns the module shape registered under the name.
+func (sr *ShapeRegistry) Lookup(name string) (qrconst.ModuleShape, bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	shape, ok := sr.names[name]
+	return shape, ok
+}
+
+// Names returns the names of the shapes, the built-in
+// ones first, then in the order they were registered.
+func (sr *ShapeRegistry) Names() []string {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	names := make([]string, len(sr.shapes))
+	for i, def := range sr.shapes {
+		names[i] = def.name
+	}
+
+	return names
+}
+
+// get returns the definition of the shape. Shapes missing
+// from the registry are drawn as squares.
+func (sr *ShapeRegistry) get(shape qrconst.ModuleShape) *shapeDef {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	if shape < 0 || int(shape) >= len(sr.shapes) {
+		return sr.shapes[qrconst.Square]
+	}
+	return sr.shapes[shape]
+}
+
+// newShapeDef checks the shape, and converts its symbols to layers.
+func newShapeDef(shape Shape) (*shapeDef, error) {
+	if !validShapeName(shape.Name) {
+		return nil, fmt.Errorf("invalid shape name %q", shape.Name)
+	}
+	if shape.Render == nil {
+		return nil, fmt.Errorf("shape %s: missing render function", shape.Name)
+	}
+	if len(shape.Symbols) == 0 {
+		return nil, fmt.Errorf("shape %s: no symbols", shape.Name)
+	}
+
+	def := &shapeDef{
+		name:    shape.Name,
+		symbols: make(map[string][]tables.ShapeLayer, len(shape.Symbols)),
+		raster:  shape.Raster,
+		render:  shape.Render,
+		merge:   shape.Merge,
+	}
+	for _, name := range slices.Sorted(maps.Keys(shape.Symbols)) {
+		if !validSymbolName(name) {
+			return nil, fmt.Errorf("shape %s: invalid symbol name %q", shape.Name, name)
+		}
+
+		d := shape.Symbols[name]
+		if d == "" {
+			if shape.Raster == nil {
+				return nil, fmt.Errorf("shape %s: symbol %s has neither path data nor raster function", shape.Name, name)
+			}
+			d = traceRaster(func(px, py int) bool {
+				return shape.Raster(name, px, py, rasterTraceScale)
+			}, rasterTraceScale)
+		} else if _, err := vector.ParsePath(d); err != nil {
+			return nil, fmt.Errorf("shape %s: symbol %s: %w", shape.Name, name, err)
+		}
+		def.symbols[name] = []tables.ShapeLayer{{Path: d, Fill: true}}
+	}
+
+	// Every lookahead is checked, as there are only a few thousands
+	for lookahead := range qrconst.Lookahead(qrconst.LookDarkModule << 1) {
+		for _, dark := range []bool{true, false} {
+			for _, name := range def.moduleSymbols(dark, lookahead) {
+				if _, ok := def.symbols[name]; !ok {
+					return nil, fmt.Errorf("shape %s: unknown symbol %q", shape.Name, name)
+				}
+			}
+		}
+	}
+
+	return def, nil
+}
+
+func validShapeName(name string) bool {
+	if name == "" || !isASCIILetter(name[0]) {
+		return false
+	}
+	return strings.IndexFunc(name, func(c rune) bool {
+		return c > 0x7f || !isASCIILetter(byte(c)) && !isASCIIDigit(byte(c))
+	}) < 0
+}
+
+// validSymbolName reports whether the name can be part of an SVG id.
+func validSymbolName(name string) bool {
+	if name == "" || !isASCIILetter(name[0]) {
+		return false
+	}
+	return strings.IndexFunc(name, func(c rune) bool {
+		return c > 0x7f || !isASCIILetter(byte(c)) && !isASCIIDigit(byte(c)) && c != '-'
+	}) < 0
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// traceRaster returns the path data of the painted pixels of a module of
+// scale pixels, one rectangle per run of pixels on every row.
+func traceRaster(painted func(px, py int) bool, scale int) string {
+	num := func(v int) string {
+		return svgNum(float64(v) / float64(scale))
+	}
+
+	var d strings.Builder
+	for py := range scale {
+		for px := 0; px < scale; {
+			if !painted(px, py) {
+				px++
+				continue
+			}
+			start := px
+			for px < scale && painted(px, py) {
+				px++
+			}
+			if d.Len() > 0 {
+				d.WriteByte(' ')
+			}
+			d.WriteString("M " + num(start) + " " + num(py) +
+				" H " + num(px) + " V " + num(py+1) + " H " + num(start) + " Z")
+		}
+	}
+
+	return d.String()
+}
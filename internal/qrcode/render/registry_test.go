@@ -0,0 +1,228 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// squareShape returns a shape drawing every dark module
+// with the square symbol of the given name.
+func squareShape(name, symbol string) Shape {
+	return Shape{
+		Name:    name,
+		Symbols: map[string]string{symbol: "M 0 0 H 1 V 1 H 0 Z"},
+		Render: func(qrconst.Lookahead) []string {
+			return []string{symbol}
+		},
+	}
+}
+
+var svgIDPattern = regexp.MustCompile(` id="([^"]*)"`)
+
+func TestShapeSymbolIDsDoNotClash(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewShapeRegistry()
+	shape, err := registry.Register(squareShape("logo", "clip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var svg strings.Builder
+	err = NewRenderer().
+		WithShapeRegistry(registry).
+		WithModuleShape(shape).
+		WithLogo(image.NewRGBA(image.Rect(0, 0, 4, 4)), DefaultLogoOptions()).
+		RenderSVG(*qr, &svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]bool{}
+	for _, m := range svgIDPattern.FindAllStringSubmatch(svg.String(), -1) {
+		if ids[m[1]] {
+			t.Errorf("id %q is defined twice", m[1])
+		}
+		ids[m[1]] = true
+	}
+	if !ids["logo__clip"] || !ids["shape-logo__clip"] {
+		t.Errorf("got ids %v, want the logo clip path and the symbol", ids)
+	}
+}
+
+func TestRegisterInvalidShape(t *testing.T) {
+	withRender := func(shape Shape, render func(qrconst.Lookahead) []string) Shape {
+		shape.Render = render
+		return shape
+	}
+	withMerge := func(shape Shape, merge func(qrconst.Lookahead) []string) Shape {
+		shape.Merge = merge
+		return shape
+	}
+	withSymbols := func(shape Shape, symbols map[string]string) Shape {
+		shape.Symbols = symbols
+		return shape
+	}
+
+	for _, tc := range []struct {
+		shape Shape
+		want  string
+	}{
+		{squareShape("", "a"), "invalid shape name"},
+		{squareShape("1st", "a"), "invalid shape name"},
+		{squareShape("my-shape", "a"), "invalid shape name"},
+		{squareShape("formé", "a"), "invalid shape name"},
+		{squareShape("circle", "a"), "already registered"},
+		{squareShape("mine", "a"), "already registered"},
+		{withRender(squareShape("other", "a"), nil), "missing render function"},
+		{withSymbols(squareShape("other", "a"), nil), "no symbols"},
+		{squareShape("other", "a b"), "invalid symbol name"},
+		{withSymbols(squareShape("other", "a"), map[string]string{"a": ""}), "neither path data nor raster function"},
+		{withSymbols(squareShape("other", "a"), map[string]string{"a": "M 0 0 Q"}), "symbol a"},
+		{withRender(squareShape("other", "a"), func(lookahead qrconst.Lookahead) []string {
+			if lookahead&qrconst.LookR != 0 {
+				return []string{"a", "b"}
+			}
+			return []string{"a"}
+		}), `unknown symbol "b"`},
+		{withMerge(squareShape("other", "a"), func(qrconst.Lookahead) []string {
+			return []string{"bridge"}
+		}), `unknown symbol "bridge"`},
+	} {
+		registry := NewShapeRegistry()
+		if _, err := registry.Register(squareShape("mine", "a")); err != nil {
+			t.Fatal(err)
+		}
+		names := registry.Names()
+
+		_, err := registry.Register(tc.shape)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("shape %q: got error %v, want %q", tc.shape.Name, err, tc.want)
+		}
+		if got := registry.Names(); len(got) != len(names) {
+			t.Errorf("shape %q: got %d shapes after the failed registration, want %d", tc.shape.Name, len(got), len(names))
+		}
+	}
+}
+
+// TestRasterShape checks a shape defined only by its raster function,
+// painting the left half of the dark modules, in PNG and SVG output.
+func TestRasterShape(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewShapeRegistry()
+	half := squareShape("half", "left")
+	half.Symbols["left"] = ""
+	half.Raster = func(symbol string, px, py, scale int) bool {
+		return px < scale/2
+	}
+	shape, err := registry.Register(half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRenderer().
+		WithShapeRegistry(registry).
+		WithModuleShape(shape).
+		WithDefaultFinder(false).
+		WithQuietZone(0)
+
+	const scale = 8
+	img := r.WithModuleSize(scale).RenderImage(*qr)
+	if got, want := img.Bounds().Dx(), qr.Size*scale; got != want {
+		t.Fatalf("got image width %d, want %d", got, want)
+	}
+	isDark := func(px, py int) bool {
+		c, _, _, _ := img.At(px, py).RGBA()
+		return c < 0x8000
+	}
+	for y := range qr.Size {
+		for x := range qr.Size {
+			left, right := isDark(x*scale+1, y*scale+scale/2), isDark(x*scale+scale-2, y*scale+scale/2)
+			if left != qr.Modules[y][x] || right {
+				t.Fatalf("module (%d, %d): got left half dark %v, right half dark %v, want %v and false", x, y, left, right, qr.Modules[y][x])
+			}
+		}
+	}
+
+	var svg bytes.Buffer
+	if err := r.RenderSVG(*qr, &svg); err != nil {
+		t.Fatal(err)
+	}
+	paint := svgPaint(t, svg.Bytes())
+	for y := range qr.Size {
+		for x := range qr.Size {
+			left := paint(vector.Point{X: float64(x) + .25, Y: float64(y) + .5}) != ""
+			right := paint(vector.Point{X: float64(x) + .75, Y: float64(y) + .5}) != ""
+			if left != qr.Modules[y][x] || right {
+				t.Fatalf("SVG module (%d, %d): got left half painted %v, right half painted %v, want %v and false", x, y, left, right, qr.Modules[y][x])
+			}
+		}
+	}
+}
+
+func TestTraceRaster(t *testing.T) {
+	got := traceRaster(func(px, py int) bool {
+		return px != 1 && py != 2
+	}, 4)
+	want := "M 0 0 H 0.25 V 0.25 H 0 Z M 0.5 0 H 1 V 0.25 H 0.5 Z " +
+		"M 0 0.25 H 0.25 V 0.5 H 0 Z M 0.5 0.25 H 1 V 0.5 H 0.5 Z " +
+		"M 0 0.75 H 0.25 V 1 H 0 Z M 0.5 0.75 H 1 V 1 H 0.5 Z"
+	if got != want {
+		t.Errorf("got path data %q, want %q", got, want)
+	}
+	if got := traceRaster(func(int, int) bool { return false }, 4); got != "" {
+		t.Errorf("got path data %q for an empty symbol, want none", got)
+	}
+}
+
+func TestShapeRegistryGet(t *testing.T) {
+	registry := NewShapeRegistry()
+	shape, err := registry.Register(squareShape("mine", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := registry.get(shape).name; got != "mine" {
+		t.Errorf("got shape %q, want mine", got)
+	}
+	if got := registry.get(qrconst.Circle).name; got != "circle" {
+		t.Errorf("got shape %q, want circle", got)
+	}
+	for _, unknown := range []qrconst.ModuleShape{-1, shape + 1, 1000} {
+		if got := registry.get(unknown); got != registry.get(qrconst.Square) {
+			t.Errorf("shape %d: got shape %q, want square", unknown, got.name)
+		}
+	}
+	// The registered shape is unknown to other registries
+	if got := NewShapeRegistry().get(shape); got.name != "square" {
+		t.Errorf("got shape %q from another registry, want square", got.name)
+	}
+}
+
+func TestPNGSettingsRendererShape(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want qrconst.ModuleShape
+	}{
+		{"circle", qrconst.Circle},
+		{"square", qrconst.Square},
+		{"notregistered", qrconst.Square},
+		{"", qrconst.Square},
+	} {
+		s := PNGSettings{Shape: tc.name}
+		if got := s.Renderer().moduleShape; got != tc.want {
+			t.Errorf("shape %q: got module shape %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
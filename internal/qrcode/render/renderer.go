@@ -1,39 +1,76 @@
 package render
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"hash/fnv"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/jpeg"
-	"image/png"
 	"io"
+	"math"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/ahmadnaufalhakim/qrgen/internal/cmykjpeg"
 	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
 	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
-	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
 )
 
 type QRRenderer struct {
 	moduleShape     qrconst.ModuleShape
 	defaultFinder   bool
-	backgroundColor color.RGBA
-	foregroundColor color.RGBA
+	backgroundColor color.NRGBA
+	foregroundColor color.NRGBA
+	backgroundInk   ink
+	foregroundInk   ink
+	backgroundPaint *gradientPaint
+	foregroundPaint *gradientPaint
 	kernelType      string
 	kernelFunc      func(radius int) []float64
 	radius          int
+	quietZone       int
+	moduleSize      int
+	outputSize      int
+	roleStyles      map[qrconst.FunctionPattern]roleStyle
+	finderStyle     *FinderStyle
+	logo            image.Image
+	logoOptions     LogoOptions
+	shapes          *ShapeRegistry
+	compactSVG      bool
+	svgIDPrefix     string
+	uniqueSVGIDs    bool
+	svgFragment     bool
+	svgTheme        bool
+	pdfOptions      PDFOptions
+	epsOptions      EPSOptions
+	encoderOptions  EncoderOptions
+	dpi             float64
+	physicalSize    float64
+	pngMetadata     bool
+	frameOptions    *FrameOptions
+	halftone        image.Image
+	halftoneOptions HalftoneOptions
 }
 
 func NewRenderer() *QRRenderer {
 	return &QRRenderer{
 		moduleShape:     qrconst.Square,
 		defaultFinder:   true,
-		backgroundColor: color.RGBA{255, 255, 255, 255},
-		foregroundColor: color.RGBA{0, 0, 0, 255},
-		kernelType:      "Lanczos2",
-		kernelFunc:      Lanczos2Kernel,
-		radius:          3,
+		backgroundColor: color.NRGBA{255, 255, 255, 255},
+		foregroundColor: color.NRGBA{0, 0, 0, 255},
+		backgroundInk:   newInk(color.NRGBA{255, 255, 255, 255}),
+		foregroundInk:   newInk(color.NRGBA{0, 0, 0, 255}),
+		kernelType:      "None",
+		quietZone:       4,
+		moduleSize:      0,
+		outputSize:      0,
+		shapes:          DefaultShapeRegistry,
+		pdfOptions:      DefaultPDFOptions(),
 	}
 }
 
@@ -44,6 +81,61 @@ func (r *QRRenderer) WithModuleShape(
 	return r
 }
 
+// WithShapeRegistry sets the registry of the module shapes passed to
+// WithModuleShape and WithRoleStyle. Shapes missing from the registry
+// are drawn as squares.
+func (r *QRRenderer) WithShapeRegistry(
+	registry *ShapeRegistry,
+) *QRRenderer {
+	r.shapes = registry
+	return r
+}
+
+// WithCompactSVG makes RenderSVG write smaller files: the connected
+// square modules of every color are merged into the outlines of their
+// regions, only the symbols of the shapes in use are defined, and the
+// modules are not indented. Only Square modules are merged, as the
+// other shapes, including registered ones, do not fill their modules;
+// they are still drawn with their symbols.
+func (r *QRRenderer) WithCompactSVG(
+	compact bool,
+) *QRRenderer {
+	r.compactSVG = compact
+	return r
+}
+
+// WithSVGIDPrefix prefixes the ids of the SVG definitions, e.g. with
+// "qr1-", so that several SVGs inlined in one page do not refer to
+// each other's definitions. The prefix must start with an ASCII letter,
+// followed by ASCII letters, digits, '-' and '_', so that the ids are
+// valid XML names and CSS class names; RenderSVG fails otherwise.
+func (r *QRRenderer) WithSVGIDPrefix(
+	prefix string,
+) *QRRenderer {
+	r.svgIDPrefix = prefix
+	return r
+}
+
+// WithUniqueSVGIDs prefixes the ids of the SVG definitions with a hash
+// of the SVG, after the prefix set by WithSVGIDPrefix. Identical SVGs
+// share their ids, which is harmless as their definitions match.
+func (r *QRRenderer) WithUniqueSVGIDs(
+	unique bool,
+) *QRRenderer {
+	r.uniqueSVGIDs = unique
+	return r
+}
+
+// WithSVGFragment makes RenderSVG write an svg element to inline in
+// HTML, without the XML namespace, and without a width and height so
+// that it is sized by the page.
+func (r *QRRenderer) WithSVGFragment(
+	fragment bool,
+) *QRRenderer {
+	r.svgFragment = fragment
+	return r
+}
+
 func (r *QRRenderer) WithDefaultFinder(
 	defaultFinder bool,
 ) *QRRenderer {
@@ -51,20 +143,52 @@ func (r *QRRenderer) WithDefaultFinder(
 	return r
 }
 
+// WithBackgroundColor sets the color of the light modules and the quiet
+// zone. Any color.Color is accepted; use color.NRGBA to specify a
+// straight (non-premultiplied) alpha, e.g. a fully transparent background.
+// PDF and EPS output keep color.CMYK and SpotColor colors as such.
 func (r *QRRenderer) WithBackgroundColor(
-	backgroundColor color.RGBA,
+	backgroundColor color.Color,
 ) *QRRenderer {
-	r.backgroundColor = backgroundColor
+	r.backgroundInk = newInk(backgroundColor)
+	r.backgroundColor = r.backgroundInk.rgb
+	r.backgroundPaint = nil
 	return r
 }
 
+// WithForegroundColor sets the color of the dark modules. Its alpha is
+// independent of the background alpha.
 func (r *QRRenderer) WithForegroundColor(
-	foregroundColor color.RGBA,
+	foregroundColor color.Color,
+) *QRRenderer {
+	r.foregroundInk = newInk(foregroundColor)
+	r.foregroundColor = r.foregroundInk.rgb
+	r.foregroundPaint = nil
+	return r
+}
+
+// WithBackgroundGradient fills the light modules and the quiet zone with
+// a gradient instead of the background color. The gradient is laid out
+// across the symbol, and extends into the quiet zone with its end colors.
+func (r *QRRenderer) WithBackgroundGradient(
+	gradient Gradient,
+) *QRRenderer {
+	r.backgroundPaint = newGradientPaint(gradient)
+	return r
+}
+
+// WithForegroundGradient fills the dark modules with a
+// gradient laid out across the whole symbol.
+func (r *QRRenderer) WithForegroundGradient(
+	gradient Gradient,
 ) *QRRenderer {
-	r.foregroundColor = foregroundColor
+	r.foregroundPaint = newGradientPaint(gradient)
 	return r
 }
 
+// WithKernelType blurs the rendered image with one of the Kernels, as
+// an effect on top of the anti-aliased edges. Kernel types missing from
+// Kernels, such as "None" (the default), disable the blur.
 func (r *QRRenderer) WithKernelType(
 	kernelType string,
 ) *QRRenderer {
@@ -83,8 +207,38 @@ func (r *QRRenderer) WithRadius(
 	return r
 }
 
+// WithQuietZone sets the width of the quiet zone around
+// the symbol, in modules.
+func (r *QRRenderer) WithQuietZone(
+	quietZone int,
+) *QRRenderer {
+	r.quietZone = max(0, quietZone)
+	return r
+}
+
+// WithModuleSize sets the number of pixels per module. A module size of 0
+// picks a size based on the QR Code version (and 51 pixels for SVG).
+func (r *QRRenderer) WithModuleSize(
+	moduleSize int,
+) *QRRenderer {
+	r.moduleSize = max(0, moduleSize)
+	return r
+}
+
+// WithOutputSize sets the exact width and height of the output in pixels,
+// overriding the module size. Modules keep a whole number of pixels, and
+// the leftover pixels are distributed evenly around the quiet zone. If the
+// output size is too small for one pixel per module, the output grows to
+// fit the symbol. An output size of 0 disables it.
+func (r *QRRenderer) WithOutputSize(
+	outputSize int,
+) *QRRenderer {
+	r.outputSize = max(0, outputSize)
+	return r
+}
+
 func (r *QRRenderer) RenderImage(qr qrcode.QRCode) image.Image {
-	return r.renderImage(qr)
+	return r.renderFramedImage(qr)
 }
 
 func (r *QRRenderer) RenderToWriter(
@@ -92,232 +246,554 @@ func (r *QRRenderer) RenderToWriter(
 	w io.Writer,
 	format qrconst.RenderFormat,
 ) error {
-	img := r.renderImage(qr)
+	switch format {
+	case qrconst.RenderPDF:
+		return r.RenderPDF(qr, w)
+	case qrconst.RenderEPS:
+		return r.RenderEPS(qr, w)
+	}
 
+	img := r.renderFramedImage(qr)
+
+	quality := r.encoderOptions.JPEGQuality
+	if quality <= 0 {
+		quality = jpeg.DefaultQuality
+	}
+	// Files recording the resolution or the settings are encoded
+	// first, and the metadata inserted afterwards
+	dpi := r.metadataDPI()
+	settings := r.pngMetadata && isPNG(format)
+	out := w
+	var buf bytes.Buffer
+	if dpi > 0 || settings {
+		out = &buf
+	}
+
+	var err error
 	switch format {
 	case qrconst.RenderPNG:
-		return png.Encode(w, img)
+		err = r.encodePNG(out, img)
+	case qrconst.RenderPalettedPNG:
+		err = r.encodePalettedPNG(out, img)
+	case qrconst.RenderMonoPNG:
+		err = r.encodeMonoPNG(out, img)
 	case qrconst.RenderJPEG:
-		return jpeg.Encode(w, img, nil)
+		// JPEG has no alpha channel
+		err = jpeg.Encode(out, flatten(img, color.White), &jpeg.Options{Quality: quality})
+	case qrconst.RenderCMYKJPEG:
+		err = cmykjpeg.Encode(out, r.cmykImage(img), &cmykjpeg.Options{Quality: quality, DPI: dpi})
+	case qrconst.RenderGIF:
+		err = encodeGIF(out, img)
+	case qrconst.RenderBMP:
+		err = encodeBMP(out, img)
+	case qrconst.RenderTIFF:
+		err = encodeTIFF(out, img)
 	default:
 		return fmt.Errorf("unsupported render format")
 	}
+	if err != nil || out == w {
+		return err
+	}
+
+	data := buf.Bytes()
+	if dpi > 0 {
+		data = withDPI(format, data, dpi)
+	}
+	if settings {
+		data = pngWithSettings(data, r.pngSettings(qr))
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func isPNG(format qrconst.RenderFormat) bool {
+	switch format {
+	case qrconst.RenderPNG, qrconst.RenderPalettedPNG, qrconst.RenderMonoPNG:
+		return true
+	}
+	return false
 }
 
 func (r *QRRenderer) RenderSVG(qr qrcode.QRCode, w io.Writer) error {
-	size := len(qr.Modules)
-	quietZone := 4
-	totalSize := size + quietZone*2
+	if !validSVGIDPrefix(r.svgIDPrefix) {
+		return fmt.Errorf("invalid SVG id prefix %q", r.svgIDPrefix)
+	}
+	if r.uniqueSVGIDs {
+		// Render once to hash the SVG, then with the hash in the ids
+		c := *r
+		c.uniqueSVGIDs = false
+		h := fnv.New32a()
+		if err := c.RenderSVG(qr, h); err != nil {
+			return err
+		}
+		c.svgIDPrefix = fmt.Sprintf("%sqr%08x-", r.svgIDPrefix, h.Sum32())
+		return c.RenderSVG(qr, w)
+	}
+
+	if r.logo != nil && r.logoOptions.Mode == qrconst.LogoClear {
+		qr = r.clearLogoArea(qr)
+	}
+	if r.finderStyle != nil {
+		qr = clearFinders(qr)
+	}
+
+	// Writes are buffered, and the first error is returned by Flush
+	bw := bufio.NewWriter(w)
+	w = bw
+
+	quietZone := r.quietZone
+	scale, margin, imgSize := r.layoutWithScale(qr, 51)
+
+	// The view box is expressed in modules, with the first module at
+	// (quietZone, quietZone), so that every module lands on whole pixels
+	viewBoxOriginF := float64(quietZone) - float64(margin)/float64(scale)
+	viewBoxSizeF := float64(imgSize) / float64(scale)
+	viewX, viewY, viewW, viewH := viewBoxOriginF, viewBoxOriginF, viewBoxSizeF, viewBoxSizeF
+	width, height := imgSize, imgSize
+
+	// A frame extends the view box beyond the quiet zone
+	var frame *frameLayout
+	if r.frameOptions != nil {
+		frame = r.frameLayout(qr, scale)
+		viewX -= frame.left
+		viewY -= frame.top
+		viewW += frame.left + frame.right
+		viewH += frame.top + frame.bottom
+		width = int(math.Round(viewW * float64(scale)))
+		height = int(math.Round(viewH * float64(scale)))
+		// Conic gradient patterns cover the square around the view box
+		viewBoxOriginF = min(viewX, viewY)
+		viewBoxSizeF = max(viewX+viewW, viewY+viewH) - viewBoxOriginF
+	}
+	viewBox := strings.Join([]string{
+		strconv.FormatFloat(viewX, 'f', -1, 64), strconv.FormatFloat(viewY, 'f', -1, 64),
+		strconv.FormatFloat(viewW, 'f', -1, 64), strconv.FormatFloat(viewH, 'f', -1, 64),
+	}, " ")
+	viewRect := fmt.Sprintf(
+		`x="%s" y="%s" width="%s" height="%s"`,
+		strconv.FormatFloat(viewX, 'f', -1, 64), strconv.FormatFloat(viewY, 'f', -1, 64),
+		strconv.FormatFloat(viewW, 'f', -1, 64), strconv.FormatFloat(viewH, 'f', -1, 64),
+	)
 
-	fmt.Fprintf(
-		w, `<svg
+	// Group the modules by color, or by role when themed. In compact
+	// mode, the square modules of every group are merged into the
+	// outlines of their regions.
+	shapes := r.styleShapeDefs()
+	groups := r.moduleGroups(qr, r.svgTheme, r.compactSVG)
+	bodies := make([]strings.Builder, len(groups))
+	used := make(map[*shapeDef]bool)
+	for i, group := range groups {
+		body := &bodies[i]
+		for _, use := range group.uses {
+			used[use.def] = true
+			if r.compactSVG {
+				fmt.Fprintf(
+					body, `<use href="#%s" x="%d" y="%d"/>`,
+					symbolID(r.svgIDPrefix, use.def, use.name), quietZone+use.x, quietZone+use.y,
+				)
+				continue
+			}
+			fmt.Fprintf(
+				body, "\t\t<use href=\"#%s\" x=\"%d\" y=\"%d\"/>\n",
+				symbolID(r.svgIDPrefix, use.def, use.name), quietZone+use.x, quietZone+use.y,
+			)
+		}
+		if group.tiles != nil {
+			body.WriteString(`<path d="` + squareOutline(group.tiles, quietZone, quietZone) + `" fill="currentColor"/>`)
+		}
+	}
+
+	if r.svgFragment {
+		fmt.Fprintf(
+			w, `<svg
+	viewBox="%s"
+	shape-rendering="geometricPrecision"
+>
+`,
+			viewBox,
+		)
+	} else {
+		fmt.Fprintf(
+			w, `<svg
 	xmlns="http://www.w3.org/2000/svg"
-	viewBox="0 0 %d %d"
+	viewBox="%s"
 	shape-rendering="geometricPrecision"
 	width="%d" height="%d"
 >
 `,
-		totalSize, totalSize,
-		totalSize*51, totalSize*51,
-	)
+			viewBox,
+			width, height,
+		)
+	}
+
+	if r.svgTheme {
+		rules := []svgThemeRule{{"background", "fill", "var(--qr-background, " + r.svgBackgroundFill() + ")"}}
+		for i, group := range groups {
+			if bodies[i].Len() == 0 {
+				continue
+			}
+			if group.key == "" {
+				rules = append(rules, svgThemeRule{"foreground", "fill", "var(--qr-foreground, " + r.svgForegroundFill() + ")"})
+				continue
+			}
+			var c color.Color
+			if group.style.hasColor {
+				c = group.style.color
+			}
+			rules = append(rules, svgThemeRule{group.key, "color", r.svgThemePaint(group.key, c)})
+		}
+		if r.finderStyle != nil {
+			rules = append(rules, r.svgEyeRules()...)
+		}
+		r.writeSVGStyle(w, rules)
+	}
 
-	symbols := tables.PathSymbols[r.moduleShape]
-	if r.defaultFinder && r.moduleShape != qrconst.Square {
-		symbols = append(symbols, tables.PathSymbols[qrconst.Square]...)
-	}
-	fmt.Fprint(
-		w,
-		`	<defs>
-		`+strings.ReplaceAll(
-			strings.Join(symbols, "\n\t\t"),
-			"0,0,0,1.",
-			strconv.Itoa(int(r.foregroundColor.R))+","+strconv.Itoa(int(r.foregroundColor.G))+","+strconv.Itoa(int(r.foregroundColor.B))+","+strconv.FormatFloat(float64(r.backgroundColor.A)/255.0, 'f', -1, 64),
-		)+`
-	</defs>
+	// Symbols are filled with the color of the group of their modules.
+	// The compact mode leaves out the symbols of the unused shapes.
+	fmt.Fprint(w, "\t<defs>\n")
+	written := make(map[*shapeDef]bool)
+	for _, shape := range r.styleShapes() {
+		// Shapes missing from the registry share the square symbols
+		def := shapes[shape]
+		if !written[def] && (used[def] || !r.compactSVG) {
+			writeShapeSymbols(w, r.svgIDPrefix, def)
+			written[def] = true
+		}
+	}
+	if r.backgroundPaint != nil {
+		r.backgroundPaint.writeSVGDef(w, r.svgID("bg__gradient"), float64(quietZone), float64(qr.Size), viewBoxOriginF, viewBoxSizeF)
+	}
+	if r.foregroundPaint != nil {
+		r.foregroundPaint.writeSVGDef(w, r.svgID("fg__gradient"), float64(quietZone), float64(qr.Size), viewBoxOriginF, viewBoxSizeF)
+	}
+	fmt.Fprint(w, `	</defs>
+`)
+
+	// A themed background is always drawn, so that it can be set
+	if r.backgroundPaint != nil || r.backgroundColor.A > 0 || r.svgTheme {
+		fmt.Fprintf(
+			w, `	<rect%s %s fill="%s"/>
 `,
-	)
+			r.svgClassAttr("background"),
+			viewRect,
+			r.svgBackgroundFill(),
+		)
+	}
+
+	for i, group := range groups {
+		body := &bodies[i]
+		if body.Len() == 0 {
+			continue
+		}
+		if r.compactSVG {
+			body.WriteString("\n")
+		}
 
-	fmt.Fprintf(
-		w, `	<rect width="100%%" height="100%%" fill="rgba(%d, %d, %d, %f)"/>
+		// With a foreground gradient, the foreground modules are drawn in
+		// white into a mask, through which the gradient is painted
+		if group.key == "" && r.foregroundPaint != nil {
+			fmt.Fprintf(
+				w, `	<mask id="%s" maskUnits="userSpaceOnUse" %s>
+	<g color="rgb(255,255,255)">
+%s	</g>
+	</mask>
+	<rect%s %s fill="url(#%s)" mask="url(#%s)"/>
 `,
-		r.backgroundColor.R, r.backgroundColor.G, r.backgroundColor.B, float64(r.backgroundColor.A)/255.0,
-	)
+				r.svgID("modules__mask"),
+				viewRect,
+				body.String(),
+				r.svgClassAttr("foreground"),
+				viewRect,
+				r.svgID("fg__gradient"), r.svgID("modules__mask"),
+			)
+			continue
+		}
 
-	var pathRenderFunc func(lookahead qrconst.Lookahead) []string
-	var pathMergeFunc func(lookahead qrconst.Lookahead) []string
-
-	for y, row := range qr.Modules {
-		for x, module := range row {
-			lookahead := buildLookahead(qr, x, y)
-			if lookahead.Has(qrconst.LookFinder) && r.defaultFinder {
-				pathRenderFunc = tables.PathRenderFunctions[qrconst.Square]
-				pathMergeFunc = tables.PathMergeFunctions[qrconst.Square]
-			} else {
-				pathRenderFunc = tables.PathRenderFunctions[r.moduleShape]
-				pathMergeFunc = tables.PathMergeFunctions[r.moduleShape]
-			}
+		groupColor := rgbaComponents(r.foregroundColor)
+		if group.style.hasColor {
+			groupColor = rgbaComponents(group.style.color)
+		}
+		class := ""
+		if group.key != "" {
+			class = r.svgClassAttr(group.key)
+		}
+		fmt.Fprintf(w, "\t<g%s color=\"rgba(%s)\">\n%s\t</g>\n", class, groupColor, body.String())
+	}
 
-			if module {
-				svgPaths := pathRenderFunc(lookahead)
-
-				for _, path := range svgPaths {
-					if strings.Contains(path, "stroke=\"rgba(0,0,0,1.)\"") {
-						path = strings.ReplaceAll(
-							path,
-							"stroke=\"rgba(0,0,0,1.)\"",
-							fmt.Sprintf(
-								"stroke=\"rgba(%d,%d,%d,%f)\"",
-								r.foregroundColor.R, r.foregroundColor.G, r.foregroundColor.B, float64(r.backgroundColor.A)/255.0,
-							),
-						)
-					}
-					if strings.Contains(path, "fill=\"rgba(0,0,0,1.)\"") {
-						path = strings.ReplaceAll(
-							path,
-							"fill=\"rgba(0,0,0,1.)\"",
-							fmt.Sprintf(
-								"fill=\"rgba(%d,%d,%d,%f)\"",
-								r.foregroundColor.R, r.foregroundColor.G, r.foregroundColor.B, float64(r.backgroundColor.A)/255.0,
-							),
-						)
-					}
-
-					fmt.Fprint(
-						w,
-						`	`+strings.Split(path, "/>")[0]+
-							" x=\""+strconv.Itoa(quietZone+x)+"\" y=\""+strconv.Itoa(quietZone+y)+"\" />\n",
-					)
-				}
-			} else {
-				svgPaths := pathMergeFunc(lookahead)
-
-				for _, path := range svgPaths {
-					if strings.Contains(path, "stroke=\"rgba(0,0,0,1.)\"") {
-						path = strings.ReplaceAll(
-							path,
-							"stroke=\"rgba(0,0,0,1.)\"",
-							fmt.Sprintf(
-								"stroke=\"rgba(%d,%d,%d,%f)\"",
-								r.foregroundColor.R, r.foregroundColor.G, r.foregroundColor.B, float64(r.backgroundColor.A)/255.0,
-							),
-						)
-					}
-					if strings.Contains(path, "fill=\"rgba(0,0,0,1.)\"") {
-						path = strings.ReplaceAll(
-							path,
-							"fill=\"rgba(0,0,0,1.)\"",
-							fmt.Sprintf(
-								"fill=\"rgba(%d,%d,%d,%f)\"",
-								r.foregroundColor.R, r.foregroundColor.G, r.foregroundColor.B, float64(r.backgroundColor.A)/255.0,
-							),
-						)
-					}
-
-					fmt.Fprint(
-						w,
-						`	`+strings.Split(path, "/>")[0]+
-							" x=\""+strconv.Itoa(quietZone+x)+"\" y=\""+strconv.Itoa(quietZone+y)+"\" />\n",
-					)
-				}
-			}
+	if r.halftone != nil {
+		if err := r.writeHalftoneSVG(w, qr, float64(quietZone)); err != nil {
+			return err
 		}
 	}
 
+	if r.finderStyle != nil {
+		if err := r.writeEyesSVG(w, qr, float64(quietZone)); err != nil {
+			return err
+		}
+	}
+
+	if r.logo != nil {
+		if err := r.writeLogoSVG(w, qr, float64(quietZone)); err != nil {
+			return err
+		}
+	}
+
+	if frame != nil {
+		r.writeFrameSVG(w, frame, float64(quietZone), viewRect)
+	}
+
 	fmt.Fprintf(w, `</svg>
 `)
 
-	return nil
+	return bw.Flush()
 }
 
 func (r *QRRenderer) renderImage(qr qrcode.QRCode) image.Image {
-	version := qr.Version
-
-	// Set scale based on the QR Code version
-	var scale int
-	switch {
-	case version >= 30:
-		scale = 15
-	case version >= 20:
-		scale = 17
-	case version >= 10:
-		scale = 19
-	default:
-		scale = 21
+	if r.logo != nil && r.logoOptions.Mode == qrconst.LogoClear {
+		qr = r.clearLogoArea(qr)
+	}
+	if r.finderStyle != nil {
+		qr = clearFinders(qr)
 	}
-	margin := 4 * scale
+
+	scale, margin, imgSize := r.layout(qr)
 
 	// Prepare the image matrix
-	imgSize := qr.Size*scale + 2*margin
 	img := image.NewRGBA(image.Rect(0, 0, imgSize, imgSize))
 
-	// Background and module colors
-	bg := r.backgroundColor
-	fg := r.foregroundColor
+	// Background and module colors of every pixel
+	bg := pixelPaint(r.backgroundColor, r.backgroundPaint, qr.Size*scale, margin)
+	fg := pixelPaint(r.foregroundColor, r.foregroundPaint, qr.Size*scale, margin)
+
+	// Fill background, including the quiet zone
+	if r.backgroundPaint == nil {
+		draw.Draw(img, img.Bounds(), image.NewUniform(bg(0, 0)), image.Point{}, draw.Src)
+	} else {
+		parallelRows(imgSize, func(y0, y1 int) {
+			for py := y0; py < y1; py++ {
+				row := img.Pix[img.PixOffset(0, py):]
+				for px := range imgSize {
+					c := bg(px, py)
+					d := row[4*px : 4*px+4 : 4*px+4]
+					d[0], d[1], d[2], d[3] = c.R, c.G, c.B, c.A
+				}
+			}
+		})
+	}
+
+	styles := r.styleGrid(qr)
+	shapes := r.styleShapeDefs()
+	stamps := newShapeStamps(scale)
+
+	// Shapes may extend into their neighbors, so the coverage of the
+	// modules of every color is accumulated before painting them
+	type coverageKey struct {
+		color    color.NRGBA
+		hasColor bool
+	}
+	type moduleStamp struct {
+		stamp    *image.Alpha
+		coverage *image.Alpha
+		x, y     int
+	}
+	coverages := map[coverageKey]*image.Alpha{}
+	var (
+		coverageKeys []coverageKey
+		modules      []moduleStamp
+		// The quiet zone is left out, but for shapes spilling into it
+		symbolRect image.Rectangle
+	)
+
+	for y := range qr.Size {
+		for x := range qr.Size {
+			style := styles[y][x]
+			lookahead := r.styledLookahead(qr, styles, x, y)
+			stamp := stamps.module(shapes[style.shape], qr.Modules[y][x], lookahead)
+			if stamp == nil {
+				continue
+			}
 
-	// Fill margins
-	for x := range qr.Size + 1 {
-		for dy := range margin {
-			for dx := range margin {
-				img.Set(x*margin+dx, dy, bg)
-				img.Set(imgSize-1-x*margin-dx, imgSize-1-dy, bg)
+			key := coverageKey{style.color, style.hasColor}
+			coverage, ok := coverages[key]
+			if !ok {
+				coverage = image.NewAlpha(img.Bounds())
+				coverages[key] = coverage
+				coverageKeys = append(coverageKeys, key)
 			}
+
+			m := moduleStamp{stamp, coverage, x*scale + margin, y*scale + margin}
+			modules = append(modules, m)
+			symbolRect = symbolRect.Union(stamp.Bounds().Add(image.Pt(m.x, m.y)))
 		}
 	}
-	for y := range qr.Size + 1 {
-		for dx := range margin {
-			for dy := range margin {
-				img.Set(imgSize-margin+dx, y*margin+dy, bg)
-				img.Set(margin-1-dx, imgSize-1-y*margin-dy, bg)
+
+	// Stamp and paint the modules by bands of rows, blending
+	// them by their coverage for anti-aliased edges
+	parallelRows(imgSize, func(y0, y1 int) {
+		band := image.Rect(0, y0, imgSize, y1).Intersect(symbolRect)
+		if band.Empty() {
+			return
+		}
+		for _, m := range modules {
+			stampOver(m.coverage, m.stamp, m.x, m.y, band)
+		}
+
+		for _, key := range coverageKeys {
+			paint := fg
+			if key.hasColor {
+				paint = pixelPaint(key.color, nil, qr.Size*scale, margin)
 			}
+			blendCoverage(img, coverages[key], paint, band)
 		}
+	})
+
+	// Draw the finder patterns as whole shapes
+	if r.finderStyle != nil {
+		r.drawEyes(img, qr, scale, margin)
 	}
 
-	var pixelRenderFunc func(x, y, scale int, lookahead qrconst.Lookahead) bool
-	var pixelMergeFunc func(x, y, scale int, lookahead qrconst.Lookahead) bool
+	// Apply the optional blurring kernel
+	if r.kernelFunc != nil {
+		blurHorizontal(img, r.kernelFunc(r.radius))
+		blurVertical(img, r.kernelFunc(r.radius))
+	}
 
-	// Fill modules
-	for y := range qr.Size {
-		for x := range qr.Size {
-			startX, startY := x*scale+margin, y*scale+margin
-
-			lookahead := buildLookahead(qr, x, y)
-			if lookahead.Has(qrconst.LookFinder) && r.defaultFinder {
-				pixelRenderFunc = tables.PixelRenderFunctions[qrconst.Square]
-				pixelMergeFunc = tables.PixelMergeFunctions[qrconst.Square]
-			} else {
-				pixelRenderFunc = tables.PixelRenderFunctions[r.moduleShape]
-				pixelMergeFunc = tables.PixelMergeFunctions[r.moduleShape]
-			}
+	// The halftone image is drawn after blurring to keep it sharp
+	if r.halftone != nil {
+		r.drawHalftone(img, qr, scale, margin)
+	}
 
-			if qr.Modules[y][x] {
-				for dy := range scale {
-					for dx := range scale {
-						if pixelRenderFunc(dx, dy, scale, lookahead) {
-							img.Set(startX+dx, startY+dy, fg)
-						} else {
-							img.Set(startX+dx, startY+dy, bg)
-						}
-					}
-				}
-			} else {
-				for dy := range scale {
-					for dx := range scale {
-						if pixelMergeFunc(dx, dy, scale, lookahead) {
-							img.Set(startX+dx, startY+dy, fg)
-						} else {
-							img.Set(startX+dx, startY+dy, bg)
-						}
-					}
-				}
+	if r.logo != nil {
+		r.drawLogo(img, qr, scale, margin)
+	}
+
+	return img
+}
+
+// parallelRows calls fn concurrently on bands of rows covering [0, n).
+func parallelRows(n int, fn func(y0, y1 int)) {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	if workers <= 1 {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	band := (n + workers - 1) / workers
+	for y0 := 0; y0 < n; y0 += band {
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			fn(y0, y1)
+		}(y0, min(n, y0+band))
+	}
+	wg.Wait()
+}
+
+// blendCoverage paints the pixels of img within r, blending
+// the paint over them by their coverage.
+func blendCoverage(img *image.RGBA, coverage *image.Alpha, paint func(px, py int) color.RGBA, r image.Rectangle) {
+	r = r.Intersect(coverage.Bounds()).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		pix := img.Pix[img.PixOffset(r.Min.X, py):]
+		cov := coverage.Pix[coverage.PixOffset(r.Min.X, py):]
+		for i := range r.Dx() {
+			c := uint32(cov[i])
+			if c == 0 {
+				continue
+			}
+			src := paint(r.Min.X+i, py)
+			d := pix[4*i : 4*i+4 : 4*i+4]
+			if c == 255 && src.A == 255 {
+				d[0], d[1], d[2], d[3] = src.R, src.G, src.B, src.A
+				continue
 			}
+
+			// Premultiplied source over, scaled by the coverage,
+			// in units of 1/255² so that it rounds once
+			a := 255*255 - uint32(src.A)*c
+			d[0] = uint8((uint32(src.R)*c*255 + uint32(d[0])*a + 255*255/2) / (255 * 255))
+			d[1] = uint8((uint32(src.G)*c*255 + uint32(d[1])*a + 255*255/2) / (255 * 255))
+			d[2] = uint8((uint32(src.B)*c*255 + uint32(d[2])*a + 255*255/2) / (255 * 255))
+			d[3] = uint8((uint32(src.A)*c*255 + uint32(d[3])*a + 255*255/2) / (255 * 255))
 		}
 	}
+}
 
-	// Apply blurring kernel
-	blurHorizontal(img, r.kernelFunc(r.radius))
-	blurVertical(img, r.kernelFunc(r.radius))
+// pixelPaint returns the premultiplied color of every pixel, painted
+// either with the solid color c or with the gradient p spanning the
+// symbol of symbolSize pixels, offset by margin pixels.
+func pixelPaint(
+	c color.NRGBA,
+	p *gradientPaint,
+	symbolSize, margin int,
+) func(px, py int) color.RGBA {
+	if p == nil {
+		solid := color.RGBAModel.Convert(c).(color.RGBA)
+		return func(_, _ int) color.RGBA {
+			return solid
+		}
+	}
 
-	return img
+	size := float64(symbolSize)
+	return func(px, py int) color.RGBA {
+		// Sample the gradient at the pixel center
+		u := (float64(px-margin) + .5) / size
+		v := (float64(py-margin) + .5) / size
+		return color.RGBAModel.Convert(p.at(u, v)).(color.RGBA)
+	}
+}
+
+// layout returns the number of pixels per module, the offset of the first
+// module from the top-left corner in pixels, and the total image size
+// for the QR Code.
+func (r *QRRenderer) layout(qr qrcode.QRCode) (scale, margin, imgSize int) {
+	if r.physicalSize > 0 {
+		scale = r.physicalScale(qr)
+		return scale, r.quietZone * scale, (qr.Size + 2*r.quietZone) * scale
+	}
+
+	version := qr.Version
+
+	// Set scale based on the QR Code version
+	var autoScale int
+	switch {
+	case version >= 30:
+		autoScale = 15
+	case version >= 20:
+		autoScale = 17
+	case version >= 10:
+		autoScale = 19
+	default:
+		autoScale = 21
+	}
+
+	return r.layoutWithScale(qr, autoScale)
+}
+
+// layoutWithScale is like layout, but uses autoScale pixels
+// per module when neither a module size nor an output size is set.
+func (r *QRRenderer) layoutWithScale(qr qrcode.QRCode, autoScale int) (scale, margin, imgSize int) {
+	modules := qr.Size + 2*r.quietZone
+
+	scale = autoScale
+	if r.moduleSize > 0 {
+		scale = r.moduleSize
+	}
+	if r.outputSize > 0 {
+		scale = max(1, r.outputSize/modules)
+	}
+
+	imgSize = modules * scale
+	margin = r.quietZone * scale
+
+	// Distribute the leftover pixels around the quiet zone
+	if r.outputSize > imgSize {
+		margin += (r.outputSize - imgSize) / 2
+		imgSize = r.outputSize
+	}
+
+	return scale, margin, imgSize
 }
 
 func buildLookahead(qr qrcode.QRCode, x, y int) qrconst.Lookahead {
@@ -368,90 +844,140 @@ func buildLookahead(qr qrcode.QRCode, x, y int) qrconst.Lookahead {
 	return lookahead
 }
 
+// blurHorizontal and blurVertical convolve the premultiplied
+// pixels of img, so that colors are weighted by their alpha
+// and transparent pixels do not bleed their color.
 func blurHorizontal(img *image.RGBA, kernel []float64) {
 	bounds := img.Bounds()
-	w := bounds.Dx()
-	h := bounds.Dy()
+	blurLines(img, kernel, bounds.Dy(), bounds.Dx(), func(y, x int) int {
+		return img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+	})
+}
+
+func blurVertical(img *image.RGBA, kernel []float64) {
+	bounds := img.Bounds()
+	blurLines(img, kernel, bounds.Dx(), bounds.Dy(), func(x, y int) int {
+		return img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+	})
+}
+
+// blurLines convolves the lines of length pixels of img, concurrently.
+// offset returns the offset in img.Pix of the i-th pixel of a line.
+func blurLines(img *image.RGBA, kernel []float64, lines, length int, offset func(line, i int) int) {
 	r := len(kernel) / 2
 
-	tmp := make([]color.RGBA, w)
+	parallelRows(lines, func(l0, l1 int) {
+		tmp := make([]color.RGBA, length)
+		for line := l0; line < l1; line++ {
+			// copy line
+			for i := range length {
+				p := img.Pix[offset(line, i):]
+				tmp[i] = color.RGBA{p[0], p[1], p[2], p[3]}
+			}
 
-	for y := range h {
-		// copy row
-		for x := range w {
-			tmp[x] = img.RGBAAt(x, y)
-		}
+			for i := range length {
+				var rSum, gSum, bSum, aSum float64
 
-		for x := range w {
-			var rSum, gSum, bSum, aSum float64
+				for k := -r; k <= r; k++ {
+					ii := min(max(i+k, 0), length-1)
 
-			for k := -r; k <= r; k++ {
-				xx := x + k
-				if xx < 0 {
-					xx = 0
-				} else if xx >= w {
-					xx = w - 1
-				}
+					color := tmp[ii]
+					weight := kernel[k+r]
 
-				color := tmp[xx]
-				weight := kernel[k+r]
+					rSum += weight * float64(color.R)
+					gSum += weight * float64(color.G)
+					bSum += weight * float64(color.B)
+					aSum += weight * float64(color.A)
+				}
 
-				rSum += weight * float64(color.R)
-				gSum += weight * float64(color.G)
-				bSum += weight * float64(color.B)
-				aSum += weight * float64(color.A)
+				c := premultipliedRGBA(rSum, gSum, bSum, aSum)
+				p := img.Pix[offset(line, i):]
+				p[0], p[1], p[2], p[3] = c.R, c.G, c.B, c.A
 			}
-
-			img.SetRGBA(x, y, color.RGBA{
-				uint8(rSum),
-				uint8(gSum),
-				uint8(bSum),
-				uint8(aSum),
-			})
 		}
+	})
+}
+
+// premultipliedRGBA rounds the convolved channels into a valid
+// premultiplied color. Kernels with negative lobes can overshoot,
+// so every channel is clamped to [0, 255] and color channels
+// never exceed the alpha.
+func premultipliedRGBA(r, g, b, a float64) color.RGBA {
+	alpha := clampUint8(a)
+	return color.RGBA{
+		min(clampUint8(r), alpha),
+		min(clampUint8(g), alpha),
+		min(clampUint8(b), alpha),
+		alpha,
 	}
 }
 
-func blurVertical(img *image.RGBA, kernel []float64) {
-	bounds := img.Bounds()
-	w := bounds.Dx()
-	h := bounds.Dy()
-	r := len(kernel) / 2
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + .5)
+	}
+}
 
-	tmp := make([]color.RGBA, h)
+// svgID returns the id of an SVG definition, with the id prefix.
+func (r *QRRenderer) svgID(id string) string {
+	return r.svgIDPrefix + id
+}
 
-	for x := range w {
-		// copy row
-		for y := range h {
-			tmp[y] = img.RGBAAt(x, y)
-		}
+// validSVGIDPrefix reports whether the prefix keeps the
+// SVG ids valid XML names and CSS class names.
+func validSVGIDPrefix(prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if !isASCIILetter(prefix[0]) {
+		return false
+	}
+	return strings.IndexFunc(prefix, func(c rune) bool {
+		return c > 0x7f || !isASCIILetter(byte(c)) && !isASCIIDigit(byte(c)) && c != '-' && c != '_'
+	}) < 0
+}
 
-		for y := range h {
-			var rSum, gSum, bSum, aSum float64
+// svgBackgroundFill returns the SVG paint of the background.
+func (r *QRRenderer) svgBackgroundFill() string {
+	if r.backgroundPaint != nil {
+		return "url(#" + r.svgID("bg__gradient") + ")"
+	}
+	return "rgba(" + rgbaComponents(r.backgroundColor) + ")"
+}
 
-			for k := -r; k <= r; k++ {
-				yy := y + k
-				if yy < 0 {
-					yy = 0
-				} else if yy >= h {
-					yy = h - 1
-				}
+// svgForegroundFill returns the SVG paint of the foreground.
+func (r *QRRenderer) svgForegroundFill() string {
+	if r.foregroundPaint != nil {
+		return "url(#" + r.svgID("fg__gradient") + ")"
+	}
+	return "rgba(" + rgbaComponents(r.foregroundColor) + ")"
+}
 
-				color := tmp[yy]
-				weight := kernel[k+r]
+// svgNum formats a coordinate for SVG output, rounded to 4 decimals.
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
 
-				rSum += weight * float64(color.R)
-				gSum += weight * float64(color.G)
-				bSum += weight * float64(color.B)
-				aSum += weight * float64(color.A)
-			}
+// rgbaComponents formats the color as the comma separated
+// components of a CSS rgba() color.
+func rgbaComponents(c color.NRGBA) string {
+	return strconv.Itoa(int(c.R)) + "," +
+		strconv.Itoa(int(c.G)) + "," +
+		strconv.Itoa(int(c.B)) + "," +
+		strconv.FormatFloat(float64(c.A)/255.0, 'f', -1, 64)
+}
 
-			img.SetRGBA(x, y, color.RGBA{
-				uint8(rSum),
-				uint8(gSum),
-				uint8(bSum),
-				uint8(aSum),
-			})
-		}
-	}
+// flatten composites img over an opaque background color,
+// for formats that do not support transparency.
+func flatten(img image.Image, background color.Color) *image.RGBA {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	return flat
 }
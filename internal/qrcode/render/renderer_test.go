@@ -0,0 +1,80 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func benchmarkRenderImage(b *testing.B, version int, shape qrconst.ModuleShape) {
+	qr, err := qrcode.NewQRBuilder("qrgen").
+		WithMinVersion(version).
+		Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if qr.Version != version {
+		b.Fatalf("got version %d, want %d", qr.Version, version)
+	}
+	r := NewRenderer().WithModuleShape(shape)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.RenderImage(*qr)
+	}
+}
+
+func BenchmarkRenderImageV1(b *testing.B) {
+	benchmarkRenderImage(b, 1, qrconst.Square)
+}
+
+func BenchmarkRenderImageV40(b *testing.B) {
+	benchmarkRenderImage(b, 40, qrconst.Square)
+}
+
+func BenchmarkRenderImageV1Circle(b *testing.B) {
+	benchmarkRenderImage(b, 1, qrconst.Circle)
+}
+
+func BenchmarkRenderImageV40Circle(b *testing.B) {
+	benchmarkRenderImage(b, 40, qrconst.Circle)
+}
+
+func TestSVGIDPrefix(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		prefix string
+		ok     bool
+	}{
+		{"", true},
+		{"qr1-", true},
+		{"Qr_2", true},
+		{"1qr", false},
+		{"-qr", false},
+		{"_qr", false},
+		{"qr 1", false},
+		{"qr.1", false},
+		{`qr"1`, false},
+		{"qr)", false},
+		{"qré", false},
+	} {
+		var svg strings.Builder
+		err := NewRenderer().
+			WithSVGIDPrefix(tc.prefix).
+			WithUniqueSVGIDs(true).
+			RenderSVG(*qr, &svg)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%q: got error %v, want ok %v", tc.prefix, err, tc.ok)
+			continue
+		}
+		if tc.ok && !strings.Contains(svg.String(), `id="`+tc.prefix+"qr") {
+			t.Errorf("%q: the ids are not prefixed", tc.prefix)
+		}
+	}
+}
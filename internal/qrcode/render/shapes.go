@@ -0,0 +1,300 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/tables"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// shapeTolerance is the largest deviation, in pixels, of the
+// flattened curves of the shapes from the exact ones.
+const shapeTolerance = .05
+
+// layerPolygons returns the polygons filling and stroking
+// the layer, scaled by scale and flattened within tolerance.
+func layerPolygons(layer tables.ShapeLayer, scale, tolerance float64) (fill, stroke [][]vector.Point) {
+	lines := vector.MustParsePath(layer.Path).
+		Transform(scale, vector.Point{}).
+		Flatten(tolerance)
+
+	if layer.Fill {
+		fill = vector.Fill(lines)
+	}
+	if layer.StrokeWidth > 0 {
+		stroke = vector.Stroke(lines, layer.StrokeWidth*scale, layer.LineCap, layer.LineJoin, tolerance)
+	}
+
+	return fill, stroke
+}
+
+// polygonBounds returns the smallest box containing the polygons.
+func polygonBounds(polygons [][]vector.Point) (minP, maxP vector.Point) {
+	minP = vector.Point{X: math.Inf(1), Y: math.Inf(1)}
+	maxP = vector.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, polygon := range polygons {
+		for _, p := range polygon {
+			minP = vector.Point{X: min(minP.X, p.X), Y: min(minP.Y, p.Y)}
+			maxP = vector.Point{X: max(maxP.X, p.X), Y: max(maxP.Y, p.Y)}
+		}
+	}
+
+	return minP, maxP
+}
+
+// symbolBounds returns the box of the symbol made of layers, in module
+// units rounded outwards to hundredths. An empty symbol spans the module.
+func symbolBounds(layers []tables.ShapeLayer) (x0, y0, x1, y1 float64) {
+	var all [][]vector.Point
+	for _, layer := range layers {
+		fill, stroke := layerPolygons(layer, 1, shapeTolerance/100)
+		all = append(append(all, fill...), stroke...)
+	}
+	if len(all) == 0 {
+		return 0, 0, 1, 1
+	}
+
+	minP, maxP := polygonBounds(all)
+	return math.Floor(minP.X*100) / 100, math.Floor(minP.Y*100) / 100,
+		math.Ceil(maxP.X*100) / 100, math.Ceil(maxP.Y*100) / 100
+}
+
+// shapeStamp returns the coverage of the symbol made of layers, for
+// modules of scale pixels. The stamp bounds are relative to the
+// top-left corner of the module, and may extend past it.
+func shapeStamp(layers []tables.ShapeLayer, scale int) *image.Alpha {
+	type pass struct {
+		polygons [][]vector.Point
+		cut      bool
+	}
+
+	var (
+		passes []pass
+		all    [][]vector.Point
+	)
+	for _, layer := range layers {
+		fill, stroke := layerPolygons(layer, float64(scale), shapeTolerance)
+		for _, polygons := range [][][]vector.Point{fill, stroke} {
+			if len(polygons) > 0 {
+				passes = append(passes, pass{polygons, layer.Cut})
+				all = append(all, polygons...)
+			}
+		}
+	}
+
+	if len(all) == 0 {
+		return image.NewAlpha(image.Rectangle{})
+	}
+	minP, maxP := polygonBounds(all)
+	bounds := image.Rect(
+		int(math.Floor(minP.X)), int(math.Floor(minP.Y)),
+		int(math.Ceil(maxP.X)), int(math.Ceil(maxP.Y)),
+	)
+
+	// Composite the layers like SVG does, each layer drawn over
+	// the ones below it, and cuts erasing them
+	stamp := image.NewAlpha(bounds)
+	for _, p := range passes {
+		coverage := vector.Coverage(p.polygons, bounds)
+		for i, c := range coverage.Pix {
+			a := uint32(stamp.Pix[i])
+			if p.cut {
+				stamp.Pix[i] = uint8((a*(255-uint32(c)) + 127) / 255)
+			} else {
+				stamp.Pix[i] = uint8(uint32(c) + (a*(255-uint32(c))+127)/255)
+			}
+		}
+	}
+
+	return stamp
+}
+
+// stampOver composites the stamp over dst within clip,
+// with the top-left corner of the module at (x, y).
+func stampOver(dst, stamp *image.Alpha, x, y int, clip image.Rectangle) {
+	r := stamp.Bounds().Add(image.Pt(x, y)).Intersect(dst.Bounds()).Intersect(clip)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		d := dst.Pix[dst.PixOffset(r.Min.X, py):]
+		s := stamp.Pix[stamp.PixOffset(r.Min.X-x, py-y):]
+		for i := range r.Dx() {
+			c := uint32(s[i])
+			d[i] = uint8(c + (uint32(d[i])*(255-c)+127)/255)
+		}
+	}
+}
+
+// rasterStamp returns the stamp of a symbol drawn
+// with the raster function of its shape.
+func rasterStamp(def *shapeDef, name string, scale int) *image.Alpha {
+	stamp := image.NewAlpha(image.Rect(0, 0, scale, scale))
+	for py := range scale {
+		for px := range scale {
+			if def.raster(name, px, py, scale) {
+				stamp.Pix[stamp.PixOffset(px, py)] = 255
+			}
+		}
+	}
+
+	return stamp
+}
+
+// shapeStamps caches the stamps of the symbols of a rendering,
+// and those of the modules, made of the symbols picked for every
+// lookahead.
+type shapeStamps struct {
+	scale   int
+	stamps  map[*shapeDef]map[string]*image.Alpha
+	modules map[moduleStampKey]*image.Alpha
+}
+
+type moduleStampKey struct {
+	def       *shapeDef
+	dark      bool
+	lookahead qrconst.Lookahead
+}
+
+func newShapeStamps(scale int) *shapeStamps {
+	return &shapeStamps{
+		scale:   scale,
+		stamps:  make(map[*shapeDef]map[string]*image.Alpha),
+		modules: make(map[moduleStampKey]*image.Alpha),
+	}
+}
+
+func (s *shapeStamps) get(def *shapeDef, name string) *image.Alpha {
+	stamps, ok := s.stamps[def]
+	if !ok {
+		stamps = make(map[string]*image.Alpha)
+		s.stamps[def] = stamps
+	}
+
+	stamp, ok := stamps[name]
+	if !ok {
+		if def.raster != nil {
+			stamp = rasterStamp(def, name, s.scale)
+		} else {
+			stamp = shapeStamp(def.symbols[name], s.scale)
+		}
+		stamps[name] = stamp
+	}
+
+	return stamp
+}
+
+// module returns the stamp of a module, or nil if it draws nothing.
+func (s *shapeStamps) module(def *shapeDef, dark bool, lookahead qrconst.Lookahead) *image.Alpha {
+	key := moduleStampKey{def, dark, lookahead}
+	if stamp, ok := s.modules[key]; ok {
+		return stamp
+	}
+
+	var stamp *image.Alpha
+	switch symbols := def.moduleSymbols(dark, lookahead); len(symbols) {
+	case 0:
+	case 1:
+		stamp = s.get(def, symbols[0])
+	default:
+		var bounds image.Rectangle
+		for _, name := range symbols {
+			bounds = bounds.Union(s.get(def, name).Bounds())
+		}
+		stamp = image.NewAlpha(bounds)
+		for _, name := range symbols {
+			stampOver(stamp, s.get(def, name), 0, 0, bounds)
+		}
+	}
+	s.modules[key] = stamp
+
+	return stamp
+}
+
+// symbolID returns the SVG id of a symbol of the shape. Symbol ids
+// start with "shape-", so that no shape and symbol names clash with
+// the other ids, e.g. "logo__clip".
+func symbolID(prefix string, def *shapeDef, name string) string {
+	return prefix + "shape-" + def.name + "__" + name
+}
+
+// writeShapeSymbols writes every symbol of the shape as SVG
+// definitions, painted with the current color.
+func writeShapeSymbols(w io.Writer, prefix string, def *shapeDef) {
+	for _, name := range slices.Sorted(maps.Keys(def.symbols)) {
+		writeShapeSymbol(w, prefix, def, name, def.symbols[name])
+	}
+}
+
+func writeShapeSymbol(w io.Writer, prefix string, def *shapeDef, name string, layers []tables.ShapeLayer) {
+	id := symbolID(prefix, def, name)
+
+	hasCut := slices.ContainsFunc(layers, func(layer tables.ShapeLayer) bool {
+		return layer.Cut
+	})
+	if !hasCut {
+		if len(layers) == 1 {
+			writeLayerSVG(w, "\t\t", `id="`+id+`" `, layers[0], "currentColor")
+			return
+		}
+		fmt.Fprintf(w, "\t\t<g id=\"%s\">\n", id)
+		for _, layer := range layers {
+			writeLayerSVG(w, "\t\t\t", "", layer, "currentColor")
+		}
+		fmt.Fprint(w, "\t\t</g>\n")
+		return
+	}
+
+	// Cut layers are drawn in black into a luminance mask,
+	// through which a rectangle of the current color is painted
+	var all [][]vector.Point
+	for _, layer := range layers {
+		fill, stroke := layerPolygons(layer, 1, shapeTolerance/100)
+		all = append(append(all, fill...), stroke...)
+	}
+	minP, maxP := polygonBounds(all)
+	x0, y0 := math.Floor(minP.X*100)/100, math.Floor(minP.Y*100)/100
+	x1, y1 := math.Ceil(maxP.X*100)/100, math.Ceil(maxP.Y*100)/100
+	box := fmt.Sprintf(
+		`x="%s" y="%s" width="%s" height="%s"`,
+		svgNum(x0), svgNum(y0), svgNum(x1-x0), svgNum(y1-y0),
+	)
+
+	variant, suffix, _ := strings.Cut(name, "--")
+	if suffix != "" {
+		suffix = "--" + suffix
+	}
+	maskID := symbolID(prefix, def, variant+"-mask"+suffix)
+
+	fmt.Fprintf(w, "\t\t<mask id=\"%s\" maskUnits=\"userSpaceOnUse\" %s>\n", maskID, box)
+	fmt.Fprintf(w, "\t\t\t<rect %s fill=\"black\"/>\n", box)
+	for _, layer := range layers {
+		paint := "white"
+		if layer.Cut {
+			paint = "black"
+		}
+		writeLayerSVG(w, "\t\t\t", "", layer, paint)
+	}
+	fmt.Fprint(w, "\t\t</mask>\n")
+	fmt.Fprintf(w, "\t\t<rect id=\"%s\" %s fill=\"currentColor\" mask=\"url(#%s)\"/>\n", id, box, maskID)
+}
+
+// writeLayerSVG writes the layer as an SVG path painted with paint.
+func writeLayerSVG(w io.Writer, indent, attrs string, layer tables.ShapeLayer, paint string) {
+	fill := "none"
+	if layer.Fill {
+		fill = paint
+	}
+	fmt.Fprintf(w, "%s<path %sd=\"%s\" fill=\"%s\"", indent, attrs, layer.Path, fill)
+	if layer.StrokeWidth > 0 {
+		fmt.Fprintf(
+			w, ` stroke="%s" stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s"`,
+			paint, svgNum(layer.StrokeWidth), layer.LineCap, layer.LineJoin,
+		)
+	}
+	fmt.Fprint(w, "/>\n")
+}
@@ -0,0 +1,317 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+	"github.com/ahmadnaufalhakim/qrgen/internal/vector"
+)
+
+// svgNode is any element of the SVG output.
+type svgNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []svgNode  `xml:",any"`
+}
+
+func (n svgNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// svgLayer is a path of a symbol, as read back from the SVG output.
+type svgLayer struct {
+	lines    []vector.Polyline
+	fill     bool
+	width    float64
+	lineCap  string
+	lineJoin string
+	cut      bool
+}
+
+func parseSVGLayer(t *testing.T, n svgNode, cut bool) svgLayer {
+	t.Helper()
+
+	path, err := vector.ParsePath(n.attr("d"))
+	if err != nil {
+		t.Fatalf("invalid path data: %v", err)
+	}
+	layer := svgLayer{
+		lines:    path.Flatten(1e-4),
+		fill:     n.attr("fill") != "none",
+		lineCap:  n.attr("stroke-linecap"),
+		lineJoin: n.attr("stroke-linejoin"),
+		cut:      cut,
+	}
+	if n.attr("stroke") != "" {
+		if layer.width, err = strconv.ParseFloat(n.attr("stroke-width"), 64); err != nil {
+			t.Fatalf("invalid stroke width: %v", err)
+		}
+	}
+
+	return layer
+}
+
+// parseSVGSymbols reads the symbols written by writeShapeSymbols,
+// keyed by id.
+func parseSVGSymbols(t *testing.T, defs []byte) map[string][]svgLayer {
+	t.Helper()
+
+	var root svgNode
+	if err := xml.Unmarshal([]byte("<defs>"+string(defs)+"</defs>"), &root); err != nil {
+		t.Fatalf("invalid SVG definitions: %v", err)
+	}
+
+	masks := map[string]svgNode{}
+	for _, n := range root.Children {
+		if n.XMLName.Local == "mask" {
+			masks[n.attr("id")] = n
+		}
+	}
+
+	symbols := map[string][]svgLayer{}
+	for _, n := range root.Children {
+		id := n.attr("id")
+		switch n.XMLName.Local {
+		case "path":
+			symbols[id] = []svgLayer{parseSVGLayer(t, n, false)}
+		case "g":
+			for _, child := range n.Children {
+				symbols[id] = append(symbols[id], parseSVGLayer(t, child, false))
+			}
+		case "rect":
+			maskID := strings.TrimSuffix(strings.TrimPrefix(n.attr("mask"), "url(#"), ")")
+			mask, ok := masks[maskID]
+			if !ok {
+				t.Fatalf("symbol %s refers to the missing mask %s", id, maskID)
+			}
+			for _, child := range mask.Children {
+				if child.XMLName.Local == "path" {
+					symbols[id] = append(symbols[id], parseSVGLayer(t, child, child.attr("fill") == "black" || child.attr("stroke") == "black"))
+				}
+			}
+		}
+	}
+
+	return symbols
+}
+
+// contains reports whether the point is painted by the layer,
+// following the SVG painting rules.
+func (l svgLayer) contains(p vector.Point) bool {
+	if l.fill && winding(l.lines, p) != 0 {
+		return true
+	}
+	if l.width <= 0 {
+		return false
+	}
+
+	hw := l.width / 2
+	for _, line := range l.lines {
+		pts := line.Points
+		if len(pts) > 1 && line.Closed && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+		n := len(pts)
+		segments := n - 1
+		if line.Closed {
+			segments = n
+		}
+
+		for i := range segments {
+			a, b := pts[i], pts[(i+1)%n]
+			from, to := 0.0, 1.0
+			if !line.Closed && l.lineCap == "square" {
+				length := math.Hypot(b.X-a.X, b.Y-a.Y)
+				if i == 0 {
+					from = -hw / length
+				}
+				if i == segments-1 {
+					to = 1 + hw/length
+				}
+			}
+			if t, d := project(p, a, b); t >= from && t <= to && d <= hw {
+				return true
+			}
+		}
+
+		for i, v := range pts {
+			end := !line.Closed && (i == 0 || i == n-1)
+			if end && l.lineCap == "round" || !end && l.lineJoin == "round" {
+				if math.Hypot(p.X-v.X, p.Y-v.Y) <= hw {
+					return true
+				}
+			}
+			if !end && l.lineJoin == "miter" && inMiter(p, pts[(i-1+n)%n], v, pts[(i+1)%n], hw) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+const miterLimitRatio = 4
+
+// inMiter reports whether p is in the miter join at v of the segments
+// prev-v and v-next: past the end of the first segment, before the
+// start of the second, and within the strips around both. Joins
+// exceeding the miter limit are beveled.
+func inMiter(p, prev, v, next vector.Point, hw float64) bool {
+	if t, d := project(p, prev, v); t < 1 || d > hw {
+		return false
+	}
+	if t, d := project(p, v, next); t > 0 || d > hw {
+		return false
+	}
+
+	// The join spreads along the outer bisector, up to the miter tip
+	// at hw/sin(φ/2) from v, or the bevel at hw·sin(φ/2), where φ is
+	// the angle between the segments
+	d0x, d0y := v.X-prev.X, v.Y-prev.Y
+	d1x, d1y := next.X-v.X, next.Y-v.Y
+	l0, l1 := math.Hypot(d0x, d0y), math.Hypot(d1x, d1y)
+	d0x, d0y, d1x, d1y = d0x/l0, d0y/l0, d1x/l1, d1y/l1
+	bx, by := d0x-d1x, d0y-d1y
+	bl := math.Hypot(bx, by)
+	if bl == 0 {
+		return false
+	}
+	sin := math.Hypot(d0x+d1x, d0y+d1y) / 2
+	reach := hw / sin
+	if 1/sin > miterLimitRatio {
+		reach = hw * sin
+	}
+	return ((p.X-v.X)*bx+(p.Y-v.Y)*by)/bl <= reach
+}
+
+// project returns the position of the projection of p on the segment
+// ab, as a fraction of its length, and the distance from p to the line.
+func project(p, a, b vector.Point) (t, d float64) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return 0, math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t = ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / l2
+	return t, lineDistance(p, a, b)
+}
+
+func lineDistance(p, a, b vector.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	return math.Abs((p.X-a.X)*dy-(p.Y-a.Y)*dx) / l
+}
+
+// winding returns the winding number of the implicitly
+// closed polylines around p.
+func winding(lines []vector.Polyline, p vector.Point) int {
+	w := 0
+	for _, line := range lines {
+		pts := line.Points
+		for i, a := range pts {
+			b := pts[(i+1)%len(pts)]
+			cross := (b.X-a.X)*(p.Y-a.Y) - (p.X-a.X)*(b.Y-a.Y)
+			if a.Y <= p.Y && b.Y > p.Y && cross > 0 {
+				w++
+			} else if a.Y > p.Y && b.Y <= p.Y && cross < 0 {
+				w--
+			}
+		}
+	}
+	return w
+}
+
+// TestShapeRasterMatchesSVG checks that the raster stamp of every
+// symbol covers the same area as the SVG output, read back and point
+// sampled independently from the rasterizer.
+func TestShapeRasterMatchesSVG(t *testing.T) {
+	const (
+		scale   = 24
+		samples = 8
+	)
+
+	for shape := qrconst.Square; shape <= qrconst.Pointillism; shape++ {
+		def := DefaultShapeRegistry.get(shape)
+		var defs bytes.Buffer
+		writeShapeSymbols(&defs, "", def)
+		symbols := parseSVGSymbols(t, defs.Bytes())
+
+		for name, layers := range def.symbols {
+			id := symbolID("", def, name)
+			svgLayers, ok := symbols[id]
+			if !ok {
+				t.Errorf("%s: missing from the SVG output", id)
+				continue
+			}
+
+			stamp := shapeStamp(layers, scale)
+			bounds := stamp.Bounds().Union(image.Rect(0, 0, scale, scale)).Inset(-2)
+
+			var sum, worst float64
+			for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+				for px := bounds.Min.X; px < bounds.Max.X; px++ {
+					inside := 0
+					for sy := range samples {
+						for sx := range samples {
+							p := vector.Point{
+								X: (float64(px) + (float64(sx)+.5)/samples) / scale,
+								Y: (float64(py) + (float64(sy)+.5)/samples) / scale,
+							}
+							painted := false
+							for _, layer := range svgLayers {
+								if layer.contains(p) {
+									painted = !layer.cut
+								}
+							}
+							if painted {
+								inside++
+							}
+						}
+					}
+
+					want := float64(inside) / samples / samples
+					got := float64(stamp.AlphaAt(px, py).A) / 255
+					diff := math.Abs(got - want)
+					sum += diff
+					worst = max(worst, diff)
+				}
+			}
+
+			mean := sum / float64(bounds.Dx()*bounds.Dy())
+			if mean > .01 || worst > .35 {
+				t.Errorf("%s: raster differs from SVG, mean %.4f, worst %.3f", id, mean, worst)
+			}
+		}
+	}
+}
+
+// TestShapeFunctionsReferToSymbols checks that the symbols picked
+// for every lookahead are defined.
+func TestShapeFunctionsReferToSymbols(t *testing.T) {
+	for shape := qrconst.Square; shape <= qrconst.Pointillism; shape++ {
+		def := DefaultShapeRegistry.get(shape)
+		for lookahead := range qrconst.Lookahead(1 << 13) {
+			for _, dark := range []bool{true, false} {
+				for _, name := range def.moduleSymbols(dark, lookahead) {
+					if _, ok := def.symbols[name]; !ok {
+						t.Fatalf("%s: symbol %q is not defined", shape, name)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package render
+
+import (
+	"image/color"
+	"maps"
+	"slices"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// ModuleStyle is the shape and the color of the modules of a role.
+type ModuleStyle struct {
+	Shape qrconst.ModuleShape
+	// Color is the color of the dark modules. A nil
+	// color uses the foreground color or gradient.
+	Color color.Color
+}
+
+// WithRoleStyle styles the modules of a function pattern role, e.g.
+// qrconst.FPAlignment, or qrconst.FPMessageBit for the data modules
+// (including the remainder bits). Role styles take precedence over
+// WithModuleShape and WithDefaultFinder, and modules only merge with
+// neighbors of the same style.
+func (r *QRRenderer) WithRoleStyle(
+	role qrconst.FunctionPattern,
+	style ModuleStyle,
+) *QRRenderer {
+	s := roleStyle{shape: style.Shape}
+	if style.Color != nil {
+		s.ink = newInk(style.Color)
+		s.color = s.ink.rgb
+		s.hasColor = true
+	}
+
+	if r.roleStyles == nil {
+		r.roleStyles = make(map[qrconst.FunctionPattern]roleStyle)
+	}
+	r.roleStyles[role] = s
+	return r
+}
+
+// roleStyle is a comparable ModuleStyle.
+type roleStyle struct {
+	shape    qrconst.ModuleShape
+	color    color.NRGBA
+	ink      ink
+	hasColor bool
+}
+
+// moduleStyle returns the style of a module of the given pattern.
+func (r *QRRenderer) moduleStyle(pattern qrconst.FunctionPattern) roleStyle {
+	role := pattern
+	if role.IsUnoccupied() {
+		role = qrconst.FPMessageBit
+	}
+
+	if style, ok := r.roleStyles[role]; ok {
+		return style
+	}
+	if role.IsFinder() && r.defaultFinder {
+		return roleStyle{shape: qrconst.Square}
+	}
+	return roleStyle{shape: r.moduleShape}
+}
+
+// styleGrid returns the style of every module of the QR Code.
+func (r *QRRenderer) styleGrid(qr qrcode.QRCode) [][]roleStyle {
+	styles := make([][]roleStyle, qr.Size)
+	for y, row := range qr.Patterns {
+		styles[y] = make([]roleStyle, qr.Size)
+		for x, pattern := range row {
+			styles[y][x] = r.moduleStyle(pattern)
+		}
+	}
+
+	return styles
+}
+
+// styleShapes returns the distinct shapes used by the renderer.
+func (r *QRRenderer) styleShapes() []qrconst.ModuleShape {
+	shapes := []qrconst.ModuleShape{r.moduleShape}
+	if r.defaultFinder {
+		shapes = append(shapes, qrconst.Square)
+	}
+	for _, role := range slices.Sorted(maps.Keys(r.roleStyles)) {
+		shapes = append(shapes, r.roleStyles[role].shape)
+	}
+
+	seen := make(map[qrconst.ModuleShape]bool)
+	distinct := shapes[:0]
+	for _, shape := range shapes {
+		if !seen[shape] {
+			seen[shape] = true
+			distinct = append(distinct, shape)
+		}
+	}
+
+	return distinct
+}
+
+// styleShapeDefs returns the definitions of the shapes used
+// by the renderer, looked up once per rendering.
+func (r *QRRenderer) styleShapeDefs() map[qrconst.ModuleShape]*shapeDef {
+	defs := make(map[qrconst.ModuleShape]*shapeDef)
+	for _, shape := range r.styleShapes() {
+		defs[shape] = r.shapes.get(shape)
+	}
+
+	return defs
+}
+
+// moduleGroup holds the symbols of the modules drawn with one color.
+// The group of the empty key uses the foreground color or gradient.
+type moduleGroup struct {
+	key   string
+	style roleStyle
+	uses  []symbolUse
+	// tiles marks the square modules merged into outlines
+	tiles [][]bool
+}
+
+// symbolUse is a symbol drawn at the module (x, y).
+type symbolUse struct {
+	def  *shapeDef
+	name string
+	x, y int
+}
+
+// moduleGroups groups the symbols of the modules by color, or by role
+// with byRole, the foreground group first. Modules painted through
+// the foreground gradient always share the foreground group. With
+// mergeTiles, the square modules are marked as tiles instead.
+func (r *QRRenderer) moduleGroups(qr qrcode.QRCode, byRole, mergeTiles bool) []*moduleGroup {
+	shapes := r.styleShapeDefs()
+	styles := r.styleGrid(qr)
+	groups := []*moduleGroup{{}}
+	byKey := map[string]*moduleGroup{"": groups[0]}
+
+	for y, row := range qr.Modules {
+		for x, module := range row {
+			style := styles[y][x]
+			lookahead := r.styledLookahead(qr, styles, x, y)
+			shape := shapes[style.shape]
+
+			key := ""
+			switch {
+			case byRole && (style.hasColor || r.foregroundPaint == nil):
+				key = svgRoleName(qr.Patterns[y][x])
+			case style.hasColor:
+				key = style.ink.key()
+			}
+			group, ok := byKey[key]
+			if !ok {
+				group = &moduleGroup{key: key, style: style}
+				byKey[key] = group
+				groups = append(groups, group)
+			}
+
+			if mergeTiles && shape.tile && module {
+				if group.tiles == nil {
+					group.tiles = make([][]bool, qr.Size)
+					for i := range qr.Size {
+						group.tiles[i] = make([]bool, qr.Size)
+					}
+				}
+				group.tiles[y][x] = true
+				continue
+			}
+
+			for _, name := range shape.moduleSymbols(module, lookahead) {
+				group.uses = append(group.uses, symbolUse{shape, name, x, y})
+			}
+		}
+	}
+
+	return groups
+}
+
+var lookaheadNeighbors = []struct {
+	look   qrconst.Lookahead
+	dx, dy int
+}{
+	{qrconst.LookR, 1, 0},
+	{qrconst.LookUR, 1, -1},
+	{qrconst.LookU, 0, -1},
+	{qrconst.LookUL, -1, -1},
+	{qrconst.LookL, -1, 0},
+	{qrconst.LookDL, -1, 1},
+	{qrconst.LookD, 0, 1},
+	{qrconst.LookDR, 1, 1},
+}
+
+// styledLookahead is like buildLookahead, but with role styles set, it
+// ignores the neighbors styled differently from the module, so that
+// shapes and colors of different roles do not merge.
+func (r *QRRenderer) styledLookahead(qr qrcode.QRCode, styles [][]roleStyle, x, y int) qrconst.Lookahead {
+	lookahead := buildLookahead(qr, x, y)
+	if len(r.roleStyles) == 0 {
+		return lookahead
+	}
+
+	for _, n := range lookaheadNeighbors {
+		if lookahead.Has(n.look) && styles[y+n.dy][x+n.dx] != styles[y][x] {
+			lookahead &^= n.look
+		}
+	}
+
+	return lookahead
+}
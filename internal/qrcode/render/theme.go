@@ -0,0 +1,106 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// WithSVGTheme makes RenderSVG tag the background, the modules of every
+// role and the finder eyes with classes, e.g. "qr-data" or "qr-finder",
+// colored by a style block through CSS custom properties. The page can
+// then restyle the SVG by setting the properties on any ancestor:
+//
+//	--qr-background, --qr-foreground, --qr-data, --qr-finder,
+//	--qr-separator, --qr-alignment, --qr-timing, --qr-dark-module,
+//	--qr-format-info, --qr-version-info,
+//	--qr-finder-frame and --qr-finder-ball (with WithFinderStyle)
+//
+// Roles without a color of their own fall back to --qr-foreground, and
+// every property defaults to the colors of the renderer. With a
+// foreground gradient, these roles are all painted by --qr-foreground.
+// The classes are prefixed like the ids, which keeps the styles of
+// several SVGs inlined in one page apart when their prefixes differ.
+func (r *QRRenderer) WithSVGTheme(
+	theme bool,
+) *QRRenderer {
+	r.svgTheme = theme
+	return r
+}
+
+// svgRoleName returns the name of the role of a module pattern in the
+// classes and custom properties of the themed SVG output.
+func svgRoleName(pattern qrconst.FunctionPattern) string {
+	switch pattern {
+	case qrconst.FPFinder:
+		return "finder"
+	case qrconst.FPSeparator:
+		return "separator"
+	case qrconst.FPAlignment:
+		return "alignment"
+	case qrconst.FPTiming:
+		return "timing"
+	case qrconst.FPDarkModule:
+		return "dark-module"
+	case qrconst.FPFormatInfo:
+		return "format-info"
+	case qrconst.FPVersionInfo:
+		return "version-info"
+	}
+	return "data"
+}
+
+// svgClass returns the class of a themed SVG element, with the id prefix.
+func (r *QRRenderer) svgClass(name string) string {
+	return r.svgIDPrefix + "qr-" + name
+}
+
+// svgClassAttr returns the class attribute of an element,
+// or nothing when the SVG output is not themed.
+func (r *QRRenderer) svgClassAttr(name string) string {
+	if !r.svgTheme {
+		return ""
+	}
+	return ` class="` + r.svgClass(name) + `"`
+}
+
+// svgThemePaint returns the themed paint of an element, falling back
+// to the given color, or to the themed foreground for a nil color.
+func (r *QRRenderer) svgThemePaint(name string, c color.Color) string {
+	fallback := "var(--qr-foreground, " + r.svgForegroundFill() + ")"
+	if c != nil {
+		fallback = "rgba(" + rgbaComponents(color.NRGBAModel.Convert(c).(color.NRGBA)) + ")"
+	}
+	return "var(--qr-" + name + ", " + fallback + ")"
+}
+
+// svgThemeRule is a rule of the style block of the themed SVG output,
+// setting a property of the elements of a class.
+type svgThemeRule struct {
+	class, property, value string
+}
+
+// writeSVGStyle writes the style block of the themed SVG output.
+func (r *QRRenderer) writeSVGStyle(w io.Writer, rules []svgThemeRule) {
+	fmt.Fprint(w, "\t<style>\n")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "\t\t.%s { %s: %s; }\n", r.svgClass(rule.class), rule.property, rule.value)
+	}
+	fmt.Fprint(w, "\t</style>\n")
+}
+
+// svgEyeRules returns the rules of the frames and the balls
+// of the finder eyes, which fall back to --qr-finder.
+func (r *QRRenderer) svgEyeRules() []svgThemeRule {
+	style := r.finderStyle
+	eyePaint := func(part string, c color.Color) string {
+		return "var(--qr-" + part + ", " + r.svgThemePaint("finder", c) + ")"
+	}
+
+	return []svgThemeRule{
+		{"finder-frame", "fill", eyePaint("finder-frame", style.FrameColor)},
+		{"finder-ball", "fill", eyePaint("finder-ball", style.BallColor)},
+	}
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// renderThemedSVG returns the top-level elements of the themed SVG
+// output, and the rules of its style block.
+func renderThemedSVG(t *testing.T, r *QRRenderer, qr qrcode.QRCode) (elements []svgNode, rules []string) {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := r.WithSVGTheme(true).RenderSVG(qr, &b); err != nil {
+		t.Fatal(err)
+	}
+	var root svgNode
+	if err := xml.Unmarshal(b.Bytes(), &root); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+
+	_, style, _ := strings.Cut(b.String(), "<style>\n")
+	style, _, ok := strings.Cut(style, "\t</style>\n")
+	if !ok {
+		t.Fatal("no style block")
+	}
+	for _, rule := range strings.Split(strings.TrimSuffix(style, "\n"), "\n") {
+		rules = append(rules, strings.TrimSpace(rule))
+	}
+	return root.Children, rules
+}
+
+// themedRoles returns the classes of the roles with dark modules.
+func themedRoles(qr qrcode.QRCode, prefix string) []string {
+	roles := map[string]bool{}
+	for y, row := range qr.Modules {
+		for x, module := range row {
+			if module {
+				roles[prefix+"qr-"+svgRoleName(qr.Patterns[y][x])] = true
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(roles))
+}
+
+func TestSVGTheme(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").
+		WithMinVersion(7).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	timing := ModuleStyle{Shape: qrconst.Square, Color: color.NRGBA{200, 0, 0, 255}}
+
+	for _, prefix := range []string{"", "qr1-"} {
+		r := NewRenderer().
+			WithForegroundColor(color.NRGBA{0, 0, 100, 255}).
+			WithRoleStyle(qrconst.FPTiming, timing)
+		if prefix != "" {
+			r.WithSVGIDPrefix(prefix)
+		}
+		elements, rules := renderThemedSVG(t, r, *qr)
+
+		var classes []string
+		for _, n := range elements {
+			switch n.XMLName.Local {
+			case "g":
+				classes = append(classes, n.attr("class"))
+			case "rect":
+				if got, want := n.attr("class"), prefix+"qr-background"; got != want {
+					t.Errorf("prefix %q: got background class %q, want %q", prefix, got, want)
+				}
+			}
+		}
+		slices.Sort(classes)
+		want := themedRoles(*qr, prefix)
+		if !slices.Equal(classes, want) {
+			t.Errorf("prefix %q: got groups of classes %q, want %q", prefix, classes, want)
+		}
+
+		for _, rule := range []string{
+			"." + prefix + "qr-background { fill: var(--qr-background, rgba(255,255,255,1)); }",
+			"." + prefix + "qr-data { color: var(--qr-data, var(--qr-foreground, rgba(0,0,100,1))); }",
+			"." + prefix + "qr-finder { color: var(--qr-finder, var(--qr-foreground, rgba(0,0,100,1))); }",
+			"." + prefix + "qr-timing { color: var(--qr-timing, rgba(200,0,0,1)); }",
+		} {
+			if !slices.Contains(rules, rule) {
+				t.Errorf("prefix %q: missing the rule %q in %q", prefix, rule, rules)
+			}
+		}
+		// Every group has its rule
+		if len(rules) != len(want)+1 {
+			t.Errorf("prefix %q: got %d rules, want %d", prefix, len(rules), len(want)+1)
+		}
+	}
+}
+
+func TestSVGThemeGradient(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("qrgen").
+		WithMinVersion(7).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRenderer().
+		WithForegroundGradient(NewLinearGradient(0,
+			GradientStop{0, color.NRGBA{200, 0, 0, 255}},
+			GradientStop{1, color.NRGBA{0, 0, 200, 255}},
+		)).
+		WithRoleStyle(qrconst.FPTiming, ModuleStyle{Shape: qrconst.Square, Color: color.NRGBA{0, 150, 0, 255}})
+	elements, rules := renderThemedSVG(t, r, *qr)
+
+	// The roles without a color of their own are all drawn
+	// into the mask of the gradient, with no class of their own
+	var classes, masks []string
+	for _, n := range elements {
+		switch n.XMLName.Local {
+		case "g":
+			classes = append(classes, n.attr("class"))
+		case "mask":
+			masks = append(masks, n.attr("id"))
+		case "rect":
+			if n.attr("mask") != "" && (n.attr("class") != "qr-foreground" || n.attr("mask") != "url(#modules__mask)") {
+				t.Errorf("got masked rectangle of class %q and mask %q, want qr-foreground and modules__mask", n.attr("class"), n.attr("mask"))
+			}
+		}
+	}
+	if !slices.Equal(masks, []string{"modules__mask"}) {
+		t.Errorf("got masks %q, want modules__mask", masks)
+	}
+	if !slices.Equal(classes, []string{"qr-timing"}) {
+		t.Errorf("got groups of classes %q, want qr-timing", classes)
+	}
+
+	if want := []string{
+		".qr-background { fill: var(--qr-background, rgba(255,255,255,1)); }",
+		".qr-foreground { fill: var(--qr-foreground, url(#fg__gradient)); }",
+		".qr-timing { color: var(--qr-timing, rgba(0,150,0,1)); }",
+	}; !slices.Equal(rules, want) {
+		t.Errorf("got rules %q, want %q", rules, want)
+	}
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var funcs = map[string]any{
+	"codewordHex": codewordHex,
+	"byteHex":     byteHex,
+	"groupBits":   groupBits,
+	"add":         func(a, b int) int { return a + b },
+	"bitLen":      func(s string) int { return len(s) },
+}
+
+// report is the view model passed to the report templates.
+type report struct {
+	*qrcode.Trace
+	Size    int
+	Modules [][]bool
+}
+
+// WriteMarkdown writes a step-by-step walkthrough of the encoding
+// pipeline as a Markdown document. The QR Code must have been built
+// with tracing enabled.
+func WriteMarkdown(w io.Writer, qr qrcode.QRCode) error {
+	if qr.Trace == nil {
+		return fmt.Errorf("QR Code has no trace, build it with WithTrace(true)")
+	}
+
+	tmpl, err := texttemplate.New("report.md.tmpl").
+		Funcs(texttemplate.FuncMap(funcs)).
+		Funcs(texttemplate.FuncMap{"matrix": textMatrix}).
+		ParseFS(templateFS, "templates/report.md.tmpl")
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, newReport(qr))
+}
+
+// WriteHTML writes a step-by-step walkthrough of the encoding
+// pipeline as a standalone HTML page. The QR Code must have been
+// built with tracing enabled.
+func WriteHTML(w io.Writer, qr qrcode.QRCode) error {
+	if qr.Trace == nil {
+		return fmt.Errorf("QR Code has no trace, build it with WithTrace(true)")
+	}
+
+	tmpl, err := htmltemplate.New("report.html.tmpl").
+		Funcs(htmltemplate.FuncMap(funcs)).
+		Funcs(htmltemplate.FuncMap{"matrix": svgMatrix}).
+		ParseFS(templateFS, "templates/report.html.tmpl")
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, newReport(qr))
+}
+
+func newReport(qr qrcode.QRCode) report {
+	return report{
+		Trace:   qr.Trace,
+		Size:    qr.Size,
+		Modules: qr.Modules,
+	}
+}
+
+func codewordHex(codeword string) string {
+	b, err := strconv.ParseUint(codeword, 2, 8)
+	if err != nil {
+		return "??"
+	}
+
+	return fmt.Sprintf("%02X", b)
+}
+
+func byteHex(b uint8) string {
+	return fmt.Sprintf("%02X", b)
+}
+
+// groupBits splits a bit string into space separated groups of n bits.
+func groupBits(s string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i += n {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(s[i:min(i+n, len(s))])
+	}
+
+	return sb.String()
+}
+
+func textMatrix(modules [][]bool) string {
+	var sb strings.Builder
+	for _, row := range modules {
+		for _, module := range row {
+			if module {
+				sb.WriteString("██")
+			} else {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+func svgMatrix(modules [][]bool) htmltemplate.HTML {
+	size := len(modules)
+	quietZone := 4
+
+	var sb strings.Builder
+	fmt.Fprintf(
+		&sb,
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`,
+		size+2*quietZone, size+2*quietZone,
+		(size+2*quietZone)*8, (size+2*quietZone)*8,
+	)
+	sb.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range modules {
+		for x, module := range row {
+			if module {
+				fmt.Fprintf(&sb, "M%d %dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+
+	return htmltemplate.HTML(sb.String())
+}
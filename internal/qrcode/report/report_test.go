@@ -0,0 +1,125 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func tracedQRCode(t *testing.T, text string) qrcode.QRCode {
+	t.Helper()
+
+	qr, err := qrcode.NewQRBuilder(text).
+		WithErrorCorrectionLevel(qrconst.Q).
+		WithTrace(true).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *qr
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, tracedQRCode(t, "HELLO WORLD")); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# QR Code construction: \"HELLO WORLD\"\n",
+		"| Encoding mode | Alphanumeric |\n",
+		"| Version | 1 (21x21 modules) |\n",
+		"| Error correction level | Q |\n",
+		"- Mode indicator: `0010`\n",
+		"- Character count indicator (9 bits): `000001011`\n",
+		"13 data codewords, the last 3 of them are pad bytes (`EC`/`11`).\n",
+		"| 10 | `11101100` | `EC` |\n",
+		"- Data: `20 5B 0B 78 D1 72 DC 4D 43 40 EC 11 EC `\n",
+		"- Error correction: `A8 48 16 52 D9 36 9C 00 2E 0F B4 7A 10 `\n",
+		"208 bits, including remainder bits:\n",
+		"| 0 | 177 | 90 | 80 | 0 | 347 |\n",
+		"| 6 (chosen) | 172 | 102 | 40 | 0 | 314 |\n",
+		"Mask pattern 6 has the lowest total penalty.\n",
+		"██████████████        ██    ██████████████\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown report is missing %q", want)
+		}
+	}
+	if n := strings.Count(out, "(chosen)"); n != 1 {
+		t.Errorf("got %d chosen masks, want 1", n)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, tracedQRCode(t, "<b>&amp;</b>")); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<h1>QR Code construction: <code>&lt;b&gt;&amp;amp;&lt;/b&gt;</code></h1>",
+		"<tr><th>Encoding mode</th><td>Byte</td></tr>",
+		`<tr class="chosen">`,
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 33 33"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML report is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<b>") {
+		t.Error("HTML report does not escape the payload")
+	}
+}
+
+func TestWriteWithoutTrace(t *testing.T) {
+	qr, err := qrcode.NewQRBuilder("HELLO WORLD").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteMarkdown(&bytes.Buffer{}, *qr); err == nil {
+		t.Error("WriteMarkdown succeeded without a trace")
+	}
+	if err := WriteHTML(&bytes.Buffer{}, *qr); err == nil {
+		t.Error("WriteHTML succeeded without a trace")
+	}
+}
+
+func TestWritePadArt(t *testing.T) {
+	art := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range art.Pix {
+		art.Pix[i] = uint8(i%2) * 255
+	}
+	qr, err := qrcode.NewQRBuilder("hi").
+		WithMinVersion(3).
+		WithPadArt(art).
+		WithTrace(true).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var md, html bytes.Buffer
+	if err := WriteMarkdown(&md, *qr); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteHTML(&html, *qr); err != nil {
+		t.Fatal(err)
+	}
+
+	want := fmt.Sprintf("Mask pattern %d has the lowest total penalty, with the pad codewords drawing the art anew for every mask.", qr.MaskNum)
+	if !strings.Contains(md.String(), want) {
+		t.Errorf("Markdown report is missing %q", want)
+	}
+	if !strings.Contains(html.String(), want) {
+		t.Errorf("HTML report is missing %q", want)
+	}
+}
@@ -0,0 +1,50 @@
+package qrcode
+
+import (
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrcode/matrix"
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// Trace records the intermediate artifacts produced by every step of
+// QRBuilder.Build, in the same order the QR Code specification
+// describes them. It is only populated when tracing is enabled on the
+// builder.
+type Trace struct {
+	Text      string
+	Mode      qrconst.EncodingMode
+	CharCount int
+	Version   int
+	ECLevel   qrconst.ErrorCorrectionLevel
+
+	ModeIndicator      string
+	CharCountIndicator string
+	DataBits           []string
+
+	DataCodewords []string
+	DataBlocks    [][]string
+	ECBlocks      [][]uint8
+	MessageBits   string
+
+	// With PadArt, unless the mask is forced, the pad codewords are
+	// drawn anew for every mask, and the penalty of every mask is of
+	// its own codewords. The codewords above are of the chosen mask.
+	MaskPenalties []matrix.MaskPenalty
+	MaskNum       int
+	MaskForced    bool
+	PadArt        bool
+}
+
+// PadCodewords returns the number of trailing pad bytes that were
+// appended to fill the data capacity of the symbol, alternating
+// 0xEC/0x11 unless drawn with QRBuilder.WithPadArt.
+func (t *Trace) PadCodewords() int {
+	bits := len(t.ModeIndicator) + len(t.CharCountIndicator)
+	for _, dataBits := range t.DataBits {
+		bits += len(dataBits)
+	}
+
+	// Terminator and bit padding never take a whole codeword
+	used := (min(bits+4, len(t.DataCodewords)*8) + 7) / 8
+
+	return len(t.DataCodewords) - used
+}
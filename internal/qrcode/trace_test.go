@@ -0,0 +1,118 @@
+package qrcode
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+func TestTrace(t *testing.T) {
+	qr, err := NewQRBuilder("HELLO WORLD").
+		WithErrorCorrectionLevel(qrconst.Q).
+		WithTrace(true).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := qr.Trace
+	if tr == nil {
+		t.Fatal("no trace recorded")
+	}
+
+	if tr.Mode != qrconst.AlphanumericMode || tr.CharCount != 11 || tr.Version != 1 || tr.ECLevel != qrconst.Q {
+		t.Errorf("got mode %v, %d chars, version %d, level %v", tr.Mode, tr.CharCount, tr.Version, tr.ECLevel)
+	}
+	if tr.ModeIndicator != "0010" || tr.CharCountIndicator != "000001011" {
+		t.Errorf("got indicators %s %s, want 0010 000001011", tr.ModeIndicator, tr.CharCountIndicator)
+	}
+
+	wantData := []string{
+		"00100000", "01011011", "00001011", "01111000", "11010001", "01110010", "11011100",
+		"01001101", "01000011", "01000000", "11101100", "00010001", "11101100",
+	}
+	if !slices.Equal(tr.DataCodewords, wantData) {
+		t.Errorf("got data codewords %v, want %v", tr.DataCodewords, wantData)
+	}
+	if len(tr.DataBlocks) != 1 || !slices.Equal(tr.DataBlocks[0], wantData) {
+		t.Errorf("got data blocks %v, want a single block", tr.DataBlocks)
+	}
+	wantEC := []uint8{168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16}
+	if len(tr.ECBlocks) != 1 || !slices.Equal(tr.ECBlocks[0], wantEC) {
+		t.Errorf("got error correction blocks %v, want %v", tr.ECBlocks, wantEC)
+	}
+	if got := tr.PadCodewords(); got != 3 {
+		t.Errorf("got %d pad codewords, want 3", got)
+	}
+	if tr.MessageBits != qr.MessageBits || len(tr.MessageBits) != 26*8 {
+		t.Errorf("got %d message bits, want the 208 bits of the symbol", len(tr.MessageBits))
+	}
+
+	// The scores of the four rules, by mask
+	wantPenalties := [][4]int{
+		{177, 90, 80, 0},
+		{172, 138, 160, 0},
+		{205, 141, 160, 0},
+		{177, 144, 120, 0},
+		{195, 144, 200, 0},
+		{191, 165, 160, 0},
+		{172, 102, 40, 0},
+		{198, 120, 240, 0},
+	}
+	if len(tr.MaskPenalties) != len(wantPenalties) {
+		t.Fatalf("got %d mask penalties, want %d", len(tr.MaskPenalties), len(wantPenalties))
+	}
+	for maskNum, p := range tr.MaskPenalties {
+		got := [4]int{p.RunLength, p.BlockPattern, p.FinderPattern, p.DarkAndLight}
+		if p.MaskNum != maskNum || got != wantPenalties[maskNum] {
+			t.Errorf("mask %d: got penalties %v of mask %d, want %v", maskNum, got, p.MaskNum, wantPenalties[maskNum])
+		}
+	}
+	if tr.MaskNum != 6 || tr.MaskForced {
+		t.Errorf("got mask %d forced %v, want mask 6 not forced", tr.MaskNum, tr.MaskForced)
+	}
+}
+
+func TestTraceForcedMask(t *testing.T) {
+	maskNum := 3
+	qr, err := NewQRBuilder("HELLO WORLD").
+		WithErrorCorrectionLevel(qrconst.Q).
+		WithMaskNum(&maskNum).
+		WithTrace(true).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qr.MaskNum != 3 || qr.Trace.MaskNum != 3 || !qr.Trace.MaskForced {
+		t.Errorf("got mask %d, traced %d forced %v, want forced mask 3", qr.MaskNum, qr.Trace.MaskNum, qr.Trace.MaskForced)
+	}
+	if len(qr.Trace.MaskPenalties) != 8 {
+		t.Errorf("got %d mask penalties, want all 8", len(qr.Trace.MaskPenalties))
+	}
+}
+
+func TestPadCodewords(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		level qrconst.ErrorCorrectionLevel
+		want  int
+	}{
+		// 4+10+27 bits and a terminator fill 6 of 19 codewords
+		{"01234567", qrconst.L, 13},
+		// 4+8+8*17 bits and a terminator fill all 19 codewords
+		{"abcdefghijklmnopq", qrconst.L, 0},
+	} {
+		qr, err := NewQRBuilder(tc.text).
+			WithErrorCorrectionLevel(tc.level).
+			WithTrace(true).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := qr.Trace.PadCodewords(); got != tc.want {
+			t.Errorf("%q: got %d pad codewords, want %d", tc.text, got, tc.want)
+		}
+	}
+}
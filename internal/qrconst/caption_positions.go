@@ -0,0 +1,18 @@
+package qrconst
+
+type CaptionPosition int
+
+const (
+	CaptionBelow CaptionPosition = iota
+	CaptionAbove
+)
+
+func (cp CaptionPosition) String() string {
+	switch cp {
+	case CaptionBelow:
+		return "below"
+	case CaptionAbove:
+		return "above"
+	}
+	return "unknown"
+}
@@ -8,3 +8,11 @@ const (
 	Q ErrorCorrectionLevel = 'Q'
 	H ErrorCorrectionLevel = 'H'
 )
+
+func (ecl ErrorCorrectionLevel) String() string {
+	switch ecl {
+	case L, M, Q, H:
+		return string(ecl)
+	}
+	return "Unknown"
+}
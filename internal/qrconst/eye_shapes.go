@@ -0,0 +1,35 @@
+package qrconst
+
+// EyeShape is the shape of the frame (7x7) or
+// the ball (3x3) of a finder pattern.
+type EyeShape int
+
+const (
+	EyeSquare EyeShape = iota
+	EyeRoundedSquare
+	EyeCircle
+	EyeLeaf
+	EyeDotted
+	EyeCushion
+	EyeImage
+)
+
+func (es EyeShape) String() string {
+	switch es {
+	case EyeSquare:
+		return "square"
+	case EyeRoundedSquare:
+		return "roundedSquare"
+	case EyeCircle:
+		return "circle"
+	case EyeLeaf:
+		return "leaf"
+	case EyeDotted:
+		return "dotted"
+	case EyeCushion:
+		return "cushion"
+	case EyeImage:
+		return "image"
+	}
+	return "unknown"
+}
@@ -0,0 +1,29 @@
+package qrconst
+
+// FrameShape is the shape of the frame drawn around
+// the quiet zone of the symbol, with its caption.
+type FrameShape int
+
+const (
+	FrameNone FrameShape = iota
+	FrameBorder
+	FrameRoundedBox
+	FrameSpeechBubble
+	FrameTicket
+)
+
+func (fs FrameShape) String() string {
+	switch fs {
+	case FrameNone:
+		return "none"
+	case FrameBorder:
+		return "border"
+	case FrameRoundedBox:
+		return "roundedBox"
+	case FrameSpeechBubble:
+		return "speechBubble"
+	case FrameTicket:
+		return "ticket"
+	}
+	return "unknown"
+}
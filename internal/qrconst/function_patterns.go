@@ -53,3 +53,27 @@ func (fp FunctionPattern) IsDarkModule() bool {
 func (fp FunctionPattern) IsTiming() bool {
 	return fp == FPTiming
 }
+
+func (fp FunctionPattern) String() string {
+	switch fp {
+	case FPUnoccupied:
+		return "unoccupied"
+	case FPFinder:
+		return "finder"
+	case FPSeparator:
+		return "separator"
+	case FPAlignment:
+		return "alignment"
+	case FPTiming:
+		return "timing"
+	case FPDarkModule:
+		return "darkModule"
+	case FPFormatInfo:
+		return "formatInfo"
+	case FPVersionInfo:
+		return "versionInfo"
+	case FPMessageBit:
+		return "messageBit"
+	}
+	return "unknown"
+}
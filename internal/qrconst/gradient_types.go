@@ -0,0 +1,21 @@
+package qrconst
+
+type GradientType int
+
+const (
+	LinearGradient GradientType = iota
+	RadialGradient
+	ConicGradient
+)
+
+func (gt GradientType) String() string {
+	switch gt {
+	case LinearGradient:
+		return "linear"
+	case RadialGradient:
+		return "radial"
+	case ConicGradient:
+		return "conic"
+	}
+	return "unknown"
+}
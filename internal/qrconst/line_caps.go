@@ -0,0 +1,22 @@
+package qrconst
+
+// LineCap is the shape of the ends of a stroked path.
+type LineCap int
+
+const (
+	ButtCap LineCap = iota
+	RoundCap
+	SquareCap
+)
+
+func (lc LineCap) String() string {
+	switch lc {
+	case ButtCap:
+		return "butt"
+	case RoundCap:
+		return "round"
+	case SquareCap:
+		return "square"
+	}
+	return "unknown"
+}
@@ -0,0 +1,22 @@
+package qrconst
+
+// LineJoin is the shape of the corners of a stroked path.
+type LineJoin int
+
+const (
+	MiterJoin LineJoin = iota
+	RoundJoin
+	BevelJoin
+)
+
+func (lj LineJoin) String() string {
+	switch lj {
+	case MiterJoin:
+		return "miter"
+	case RoundJoin:
+		return "round"
+	case BevelJoin:
+		return "bevel"
+	}
+	return "unknown"
+}
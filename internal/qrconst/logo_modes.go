@@ -0,0 +1,18 @@
+package qrconst
+
+type LogoMode int
+
+const (
+	LogoClear LogoMode = iota
+	LogoDim
+)
+
+func (lm LogoMode) String() string {
+	switch lm {
+	case LogoClear:
+		return "clear"
+	case LogoDim:
+		return "dim"
+	}
+	return "unknown"
+}
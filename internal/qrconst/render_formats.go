@@ -5,4 +5,16 @@ type RenderFormat int
 const (
 	RenderPNG RenderFormat = iota
 	RenderJPEG
+	RenderPDF
+	RenderEPS
+	// RenderCMYKJPEG writes a CMYK JPEG for print
+	RenderCMYKJPEG
+	// RenderPalettedPNG writes a PNG with a palette of the exact colors,
+	// at the lowest bit depth fitting them, or a plain PNG over 256 colors
+	RenderPalettedPNG
+	// RenderMonoPNG writes a 1-bit PNG of the light and dark modules
+	RenderMonoPNG
+	RenderGIF
+	RenderBMP
+	RenderTIFF
 )
@@ -0,0 +1,611 @@
+package tables
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// ShapeLayer is an SVG path, filled and/or stroked, in module units
+// with the module spanning from (0, 0) to (1, 1). Both the raster and
+// the SVG output are derived from the layers of a shape.
+type ShapeLayer struct {
+	Path        string
+	Fill        bool
+	StrokeWidth float64
+	LineCap     qrconst.LineCap
+	LineJoin    qrconst.LineJoin
+	// Cut removes the layer from the layers below it
+	Cut bool
+}
+
+type rule struct {
+	cond   bool
+	suffix string
+}
+
+func fill(d string) ShapeLayer {
+	return ShapeLayer{Path: d, Fill: true}
+}
+
+func stroke(d string, width float64, lineCap qrconst.LineCap, lineJoin qrconst.LineJoin) ShapeLayer {
+	return ShapeLayer{Path: d, StrokeWidth: width, LineCap: lineCap, LineJoin: lineJoin}
+}
+
+func cut(layer ShapeLayer) ShapeLayer {
+	layer.Cut = true
+	return layer
+}
+
+// circlePath returns the path of a circle drawn with two arcs.
+func circlePath(cx, cy, r float64) string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return "M " + f(cx-r) + " " + f(cy) +
+		" A " + f(r) + " " + f(r) + " 0 1 0 " + f(cx+r) + " " + f(cy) +
+		" A " + f(r) + " " + f(r) + " 0 1 0 " + f(cx-r) + " " + f(cy) + " Z"
+}
+
+var (
+	moduleCircle = fill("M .5 .5 m -.5 0 a .5 .5 0 1 0 1 0 a .5 .5 0 1 0 -1 0")
+
+	leftLeaf = []ShapeLayer{
+		fill("M 0 0 Q -.04 1.04 1 1 Q 1.04 -.04 0 0 Z"),
+		cut(stroke("M 1 1 L 0 0", .04, qrconst.ButtCap, qrconst.MiterJoin)),
+	}
+	rightLeaf = []ShapeLayer{
+		fill("M 1 0 Q -.04 -.04 0 1 Q 1.04 1.04 1 0 Z"),
+		cut(stroke("M 0 1 L 1 0", .04, qrconst.ButtCap, qrconst.MiterJoin)),
+	}
+)
+
+func tiedCircleArc(d string) ShapeLayer {
+	return stroke(d, .05, qrconst.SquareCap, qrconst.RoundJoin)
+}
+
+// leaf returns the leaf with its veins cut out.
+func leaf(leaf []ShapeLayer, veins string) []ShapeLayer {
+	return append(
+		leaf[:len(leaf):len(leaf)],
+		cut(stroke(veins, .025, qrconst.SquareCap, qrconst.MiterJoin)),
+	)
+}
+
+// star returns the filled star, stroked to round its points.
+func star(d string) []ShapeLayer {
+	layer := stroke(d, .125, qrconst.RoundCap, qrconst.RoundJoin)
+	layer.Fill = true
+	return []ShapeLayer{layer}
+}
+
+// ShapeSymbols holds the layers of every variant of the module shapes,
+// keyed by the names returned by ShapeRenderFunctions and
+// ShapeMergeFunctions. Variants may extend past the module to
+// connect with their neighbors.
+var ShapeSymbols = map[qrconst.ModuleShape]map[string][]ShapeLayer{
+	qrconst.Square: {
+		"render":      {fill("M 1 0 H 0 V 1 H 1 Z")},
+		"render--r":   {fill("M 0 0 V 1 H 1.04 V 0 Z")},
+		"render--d":   {fill("M 1 0 H 0 V 1.04 H 1 Z")},
+		"render--r-d": {fill("M 0 0 V 1.04 H 1 V 1 H 1.04 V 0 Z")},
+	},
+	qrconst.Circle: {
+		"render": {moduleCircle},
+	},
+	qrconst.TiedCircle: {
+		"render":       {moduleCircle},
+		"render--ur":   {tiedCircleArc("M 1 .5 A .5 .5 0 0 0 .5 0")},
+		"render--ul":   {tiedCircleArc("M .5 0 A .5 .5 0 0 0 0 .5")},
+		"render--dl":   {tiedCircleArc("M 0 .5 A .5 .5 0 0 0 .5 1")},
+		"render--dr":   {tiedCircleArc("M .5 1 A .5 .5 0 0 0 1 .5")},
+		"render--r2ur": {tiedCircleArc("M 1 .5 v -.5 Z")},
+		"render--ur2u": {tiedCircleArc("M 1 0 h -.5 Z")},
+		"render--u2ul": {tiedCircleArc("M .5 0 h -.5 Z")},
+		"render--ul2l": {tiedCircleArc("M 0 0 v .5 Z")},
+		"render--l2dl": {tiedCircleArc("M 0 .5 v .5 Z")},
+		"render--dl2d": {tiedCircleArc("M 0 1 h .5 Z")},
+		"render--d2dr": {tiedCircleArc("M .5 1 h .5 Z")},
+		"render--dr2r": {tiedCircleArc("M 1 1 v -.5 Z")},
+		"merge--ur":    {tiedCircleArc("M 1 .5 A .5 .5 0 0 0 .5 0")},
+		"merge--ul":    {tiedCircleArc("M .5 0 A .5 .5 0 0 0 0 .5")},
+		"merge--dl":    {tiedCircleArc("M 0 .5 A .5 .5 0 0 0 .5 1")},
+		"merge--dr":    {tiedCircleArc("M .5 1 A .5 .5 0 0 0 1 .5")},
+	},
+	qrconst.HorizontalBlob: {
+		"render":     {moduleCircle},
+		"render--r":  {fill("M .5 0 V 1 H 1.5 V 0 Z")},
+		"render--ur": {fill("M .5 .5 H 1 A .5 .5 0 0 1 1.5 0 V -.5 H 1 A .5 .5 0 0 1 .5 0 Z")},
+		"render--dr": {fill("M .5 .5 V 1 A .5 .5 0 0 1 1 1.5 H 1.5 V 1 A .5 .5 0 0 1 1 .5 Z")},
+	},
+	qrconst.VerticalBlob: {
+		"render":     {moduleCircle},
+		"render--d":  {fill("M 1 .5 H 0 V 1.5 H 1 Z")},
+		"render--dl": {fill("M .5 .5 H 0 A .5 .5 0 0 1 -.5 1 V 1.5 H 0 A .5 .5 0 0 1 .5 1 Z")},
+		"render--dr": {fill("M .5 .5 V 1 A .5 .5 0 0 1 1 1.5 H 1.5 V 1 A .5 .5 0 0 1 1 .5 Z")},
+	},
+	qrconst.Blob: {
+		"render":     {moduleCircle},
+		"render--r":  {fill("M .5 0 V 1 H 1.5 V 0 Z")},
+		"render--ur": {fill("M .5 .5 H 1 A .5 .5 0 0 1 1.5 0 V -.5 H 1 A .5 .5 0 0 1 .5 0 Z")},
+		"render--d":  {fill("M 1 .5 H 0 V 1.5 H 1 Z")},
+		"render--dr": {fill("M .5 .5 V 1 A .5 .5 0 0 1 1 1.5 H 1.5 V 1 A .5 .5 0 0 1 1 .5 Z")},
+	},
+	qrconst.LeftMandorla: {
+		"render":      {fill("M 0 0 V .5 A .5 .5 0 0 0 .5 1 H 1 V .5 A .5 .5 0 0 0 .5 0 Z")},
+		"render--r":   {fill("M 0 0 V .5 A .5 .5 0 0 0 .5 1 H 1.5 V .5 A .5 .5 0 0 0 1 0 Z")},
+		"render--d":   {fill("M 0 0 V 1 A .5 .5 0 0 0 .5 1.5 H 1 V .5 A .5 .5 0 0 0 .5 0 Z")},
+		"render--r-d": {fill("M 0 0 V 1 A .5 .5 0 0 0 .5 1.5 H 1 V 1 H 1.5 V .5 A .5 .5 0 0 0 1 0 Z")},
+	},
+	qrconst.RightMandorla: {
+		"render":      {fill("M .5 0 A .5 .5 0 0 0 0 .5 V 1 H .5 A .5 .5 0 0 0 1 .5 V 0 Z")},
+		"render--r":   {fill("M .5 0 A .5 .5 0 0 0 0 .5 V 1 H 1 A .5 .5 0 0 0 1.5 .5 V 0 Z")},
+		"render--d":   {fill("M .5 0 A .5 .5 0 0 0 0 .5 V 1.5 H .5 A .5 .5 0 0 0 1 1 V 0 Z")},
+		"render--r-d": {fill("M .5 0 A .5 .5 0 0 0 0 .5 V 1.5 H .5 A .5 .5 0 0 0 1 1 A .5 .5 0 0 0 1.5 .5 V 0 Z")},
+	},
+	qrconst.LeftLeaf: {
+		"render":             leaf(leftLeaf, "M .2 0 V .2 H 0 M .4 0 V .4 H 0 M .6 0 V .6 H 0 M .8 0 V .8 H 0"),
+		"render--structural": leaf(leftLeaf, "M .4 0 V .4 H 0 M .7 0 V .7 H 0"),
+	},
+	qrconst.RightLeaf: {
+		"render":             leaf(rightLeaf, "M .8 0 V .2 H 1 M .6 0 V .4 H 1 M .4 0 V .6 H 1 M .2 0 V .8 H 1"),
+		"render--structural": leaf(rightLeaf, "M .6 0 V .4 H 1 M .3 0 V .7 H 1"),
+	},
+	qrconst.Diamond: {
+		"render": {fill("M .5 0 L 0 .5 L .5 1 L 1 .5 Z")},
+	},
+	qrconst.Pentagon: {
+		"render": {fill("M .5 0 L .02447174 .3454915 L .20610737 .9045085 L .79389263 .9045085 L .97552826 .3454915 Z")},
+	},
+	qrconst.Hexagon: {
+		"render": {fill("M .5 0 L .0669873 .25 L .0669873 .75 L .5 1 L .9330127 .75 L .9330127 .25 Z")},
+	},
+	qrconst.Octagon: {
+		"render": {fill("M .5 0 L .14644661 .14644661 L 0 .5 L .14644661 .85355339 L .5 1 L .85355339 .85355339 L 1 .5 L .85355339 .14644661 Z")},
+	},
+	qrconst.Star4: {
+		"render": star("M 1 .5 L .67 .33 L .5 0 L .33 .33 L 0 .5 L .33 .67 L .5 1 L .67 .67 L 1 .5"),
+	},
+	qrconst.Star5: {
+		"render": star("M 1 .5 L .65450849 .38774301 L .6545085 .02447174 L .44098301 .31836437 L .0954915 .20610737 L .309017 .5 L .0954915 .79389263 L .44098301 .68163563 L .6545085 .97552826 L .65450849 .61225699 L 1 .5"),
+	},
+	qrconst.Star6: {
+		"render": star("M 1 .5 L .74999988 .3556625 L .75 .0669873 L .5 .211325 L .25 .0669873 L .25000012 .3556625 L 0 .5 L .25000012 .6443375 L .25 .9330127 L .5 .788675 L .75 .9330127 L .74999988 .6443375 L 1 .5"),
+	},
+	qrconst.Star8: {
+		"render": star("M 1 .5 L .85355299 .35355356 L .85355339 .14644661 L .64644644 .14644701 L .5 0 L .35355356 .14644701 L .14644661 .14644661 L .14644701 .35355356 L 0 .5 L .14644701 .64644644 L .14644661 .85355339 L .35355356 .85355299 L .5 1 L .64644644 .85355299 L .85355339 .85355339 L .85355299 .64644644 L 1 .5"),
+	},
+	qrconst.Heart: {
+		"render": {fill("M .2921 .0807 C .1584 .0807 .05 .198 .05 .3426 C .05 .4121 .0755 .4787 .1209 .5279 L .4788 .915 C .4905 .9276 .5095 .9276 .5212 .915 L .8791 .5279 C .9245 .4787 .95 .4121 .95 .3426 C .95 .198 .8416 .0807 .7079 .0807 C .6437 .0807 .5821 .1083 .5367 .1574 L .5 .1971 L .4633 .1574 C .4179 .1083 .3563 .0807 .2921 .0807 Z")},
+	},
+	qrconst.WaterDroplet: {
+		"render": {fill("M 0 0 C .1 .1 1 .15 1 .667 Q 1 1 .667 1 C .25 1 .1 .1 0 0")},
+	},
+	qrconst.Xs: xsSymbols(),
+	qrconst.SmileyFace: {
+		"render": {
+			moduleCircle,
+			// Eyes
+			cut(fill("M .775 .333 a .075 .075 0 1 0 -.15 0 a .075 .075 0 1 0 .15 0")),
+			cut(fill("M .225 .333 a .075 .075 0 1 0 .15 0 a .075 .075 0 1 0 -.15 0")),
+			// Nose
+			cut(stroke("M .5 .45 V .55", .035, qrconst.SquareCap, qrconst.MiterJoin)),
+			// Mouth
+			cut(stroke("M .2625 .65 Q .5 .9 .7375 .65", .05, qrconst.RoundCap, qrconst.MiterJoin)),
+		},
+	},
+	qrconst.Pointillism: pointillismSymbols(),
+}
+
+// xsSymbols returns the symbols of the Xs shape: an X reaching toward
+// its right, upper-right, lower-right and lower neighbors, optionally
+// outlined by a diamond for the structural modules.
+func xsSymbols() map[string][]ShapeLayer {
+	symbols := map[string][]ShapeLayer{
+		"render":          {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L .75 1 H 1 V .75 L .75 .5 L 1 .25 V 0 H .75 Z")},
+		"render--ur":      {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L .75 1 H 1 V .75 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--r":       {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L .75 1 H 1.25 L .75 .5 L 1.25 0 H .75 Z")},
+		"render--ur-r":    {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L .75 1 H 1.25 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--dr":      {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1 .25 V 0 H .75 Z")},
+		"render--ur-dr":   {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--r-dr":    {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1 H .25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1.25 0 H .75 Z")},
+		"render--d":       {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 V 1 V .75 L .75 .5 L 1 .25 V 0 H .75 Z")},
+		"render--ur-d":    {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 V 1 V .75 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--r-d":     {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 V 1 H 1.25 L .75 .5 L 1.25 0 H .75 Z")},
+		"render--ur-r-d":  {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 V 1 H 1.25 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--dr-d":    {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1 .25 V 0 H .75 Z")},
+		"render--ur-dr-d": {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1.25 0 V -.25 H 1 Z")},
+		"render--r-dr-d":  {fill("M .5 .25 L .25 0 H 0 V .25 L .25 .5 L 0 .75 V 1.25 L .5 .75 L 1 1.25 H 1.25 V 1 L .75 .5 L 1.25 0 H .75 Z")},
+		"merge--ur":       {stroke("M 1 .5 L .5 0", .025, qrconst.RoundCap, qrconst.RoundJoin)},
+		"merge--ul":       {stroke("M .5 0 L 0 .5", .025, qrconst.RoundCap, qrconst.RoundJoin)},
+		"merge--dl":       {stroke("M 0 .5 L .5 1", .025, qrconst.RoundCap, qrconst.RoundJoin)},
+		"merge--dr":       {stroke("M .5 1 L 1 .5", .025, qrconst.RoundCap, qrconst.RoundJoin)},
+	}
+
+	structural := stroke("M 1 .5 L .5 0 L 0 .5 L .5 1 Z", .025, qrconst.RoundCap, qrconst.RoundJoin)
+	for _, suffix := range []string{"", "ur", "r", "ur-r", "dr", "ur-dr", "r-dr", "d", "ur-d", "r-d", "ur-r-d", "dr-d", "ur-dr-d", "r-dr-d"} {
+		name, structuralName := "render", "render--structural"
+		if suffix != "" {
+			name += "--" + suffix
+			structuralName += "-" + suffix
+		}
+		symbols[structuralName] = append(symbols[name][:1:1], structural)
+	}
+
+	return symbols
+}
+
+// pointillismSymbols returns the dots of the Pointillism shape, which
+// grow with the number of dark neighbors, in both dark and light modules.
+func pointillismSymbols() map[string][]ShapeLayer {
+	symbols := map[string][]ShapeLayer{
+		"render--structural": {fill(circlePath(.5, .5, .5))},
+	}
+	for neighbors := range 9 {
+		suffix := "--" + strconv.Itoa(neighbors)
+		symbols["render"+suffix] = []ShapeLayer{
+			fill(circlePath(.5, .5, pointillismRadius(neighbors, .24, .60, .16))),
+		}
+		symbols["merge"+suffix] = []ShapeLayer{
+			fill(circlePath(.5, .5, pointillismRadius(neighbors, .06, .18, .06))),
+		}
+	}
+
+	return symbols
+}
+
+func pointillismRadius(neighbors int, minR, maxR, k float64) float64 {
+	t := 1 - math.Exp(-k*float64(neighbors))
+	return minR + (maxR-minR)*t
+}
+
+var ShapeRenderFunctions = map[qrconst.ModuleShape]func(lookahead qrconst.Lookahead) []string{
+	qrconst.Square: func(lookahead qrconst.Lookahead) []string {
+		R := lookahead.Has(qrconst.LookR)
+		D := lookahead.Has(qrconst.LookD)
+
+		rules := []rule{
+			{R && D, "--r-d"},
+			{R, "--r"},
+			{D, "--d"},
+		}
+
+		for _, rule := range rules {
+			if rule.cond {
+				return []string{
+					symbol("render", rule.suffix),
+				}
+			}
+		}
+
+		return []string{
+			symbol("render", ""),
+		}
+	},
+	qrconst.Circle: func(lookahead qrconst.Lookahead) []string {
+		return []string{
+			symbol("render", ""),
+		}
+	},
+	qrconst.TiedCircle: func(lookahead qrconst.Lookahead) []string {
+		symbols := []string{
+			symbol("render", ""),
+		}
+
+		UR := lookahead.Lacks(qrconst.LookR, qrconst.LookU)
+		UL := lookahead.Lacks(qrconst.LookU, qrconst.LookL)
+		DL := lookahead.Lacks(qrconst.LookL, qrconst.LookD)
+		DR := lookahead.Lacks(qrconst.LookD, qrconst.LookR)
+
+		R2UR := lookahead.Has(qrconst.LookU) &&
+			lookahead.Lacks(qrconst.LookR, qrconst.LookUR)
+		UR2U := lookahead.Has(qrconst.LookR) &&
+			lookahead.Lacks(qrconst.LookUR, qrconst.LookU)
+		U2UL := lookahead.Has(qrconst.LookL) &&
+			lookahead.Lacks(qrconst.LookU, qrconst.LookUL)
+		UL2L := lookahead.Has(qrconst.LookU) &&
+			lookahead.Lacks(qrconst.LookUL, qrconst.LookL)
+		L2DL := lookahead.Has(qrconst.LookD) &&
+			lookahead.Lacks(qrconst.LookL, qrconst.LookDL)
+		DL2D := lookahead.Has(qrconst.LookL) &&
+			lookahead.Lacks(qrconst.LookDL, qrconst.LookD)
+		D2DR := lookahead.Has(qrconst.LookR) &&
+			lookahead.Lacks(qrconst.LookD, qrconst.LookDR)
+		DR2R := lookahead.Has(qrconst.LookD) &&
+			lookahead.Lacks(qrconst.LookDR, qrconst.LookR)
+
+		symbols = applyRules(
+			symbols, "render",
+			[]rule{
+				{UR, "--ur"},
+				{UL, "--ul"},
+				{DL, "--dl"},
+				{DR, "--dr"},
+
+				{R2UR, "--r2ur"},
+				{UR2U, "--ur2u"},
+				{U2UL, "--u2ul"},
+				{UL2L, "--ul2l"},
+				{L2DL, "--l2dl"},
+				{DL2D, "--dl2d"},
+				{D2DR, "--d2dr"},
+				{DR2R, "--dr2r"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.HorizontalBlob: func(lookahead qrconst.Lookahead) []string {
+		symbols := []string{
+			symbol("render", ""),
+		}
+
+		R := lookahead.Has(qrconst.LookR)
+
+		UR := lookahead.Has(qrconst.LookUR) &&
+			lookahead.Lacks(qrconst.LookR, qrconst.LookU)
+		DR := lookahead.Has(qrconst.LookDR) &&
+			lookahead.Lacks(qrconst.LookD, qrconst.LookR)
+
+		symbols = applyRules(
+			symbols, "render",
+			[]rule{
+				{R, "--r"},
+
+				{UR, "--ur"},
+				{DR, "--dr"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.VerticalBlob: func(lookahead qrconst.Lookahead) []string {
+		symbols := []string{
+			symbol("render", ""),
+		}
+
+		D := lookahead.Has(qrconst.LookD)
+
+		DL := lookahead.Has(qrconst.LookDL) &&
+			lookahead.Lacks(qrconst.LookL, qrconst.LookD)
+		DR := lookahead.Has(qrconst.LookDR) &&
+			lookahead.Lacks(qrconst.LookD, qrconst.LookR)
+
+		symbols = applyRules(
+			symbols, "render",
+			[]rule{
+				{D, "--d"},
+
+				{DL, "--dl"},
+				{DR, "--dr"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.Blob: func(lookahead qrconst.Lookahead) []string {
+		symbols := []string{
+			symbol("render", ""),
+		}
+
+		R := lookahead.Has(qrconst.LookR)
+		UR := lookahead.Has(qrconst.LookUR)
+
+		D := lookahead.Has(qrconst.LookD)
+		DR := lookahead.Has(qrconst.LookDR)
+
+		symbols = applyRules(
+			symbols, "render",
+			[]rule{
+				{R, "--r"},
+				{UR, "--ur"},
+				{D, "--d"},
+				{DR, "--dr"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.LeftMandorla:  mandorlaSymbols,
+	qrconst.RightMandorla: mandorlaSymbols,
+	qrconst.LeftLeaf:      structuralSymbols,
+	qrconst.RightLeaf:     structuralSymbols,
+	qrconst.Diamond:       singleSymbol,
+	qrconst.Pentagon:      singleSymbol,
+	qrconst.Hexagon:       singleSymbol,
+	qrconst.Octagon:       singleSymbol,
+	qrconst.Star4:         singleSymbol,
+	qrconst.Star5:         singleSymbol,
+	qrconst.Star6:         singleSymbol,
+	qrconst.Star8:         singleSymbol,
+	qrconst.Heart:         singleSymbol,
+	qrconst.WaterDroplet:  singleSymbol,
+	qrconst.Xs: func(lookahead qrconst.Lookahead) []string {
+		R := lookahead.Has(qrconst.LookR)
+		UR := lookahead.Has(qrconst.LookUR)
+
+		D := lookahead.Has(qrconst.LookD)
+		DR := lookahead.Has(qrconst.LookDR)
+
+		rules := []rule{
+			{UR && DR && D, "ur-dr-d"},
+			{R && DR && D, "r-dr-d"},
+			{DR && D, "dr-d"},
+			{UR && R && D, "ur-r-d"},
+			{UR && D, "ur-d"},
+			{R && D, "r-d"},
+			{D, "d"},
+
+			{UR && DR, "ur-dr"},
+			{R && DR, "r-dr"},
+			{DR, "dr"},
+			{UR && R, "ur-r"},
+			{UR, "ur"},
+			{R, "r"},
+		}
+
+		var prefix string
+		if lookahead.Has(qrconst.LookStructural) {
+			prefix += "--structural"
+		}
+
+		for _, rule := range rules {
+			if rule.cond {
+				if prefix == "" {
+					return []string{
+						symbol("render", "--"+rule.suffix),
+					}
+				} else {
+					return []string{
+						symbol("render", prefix+"-"+rule.suffix),
+					}
+				}
+			}
+		}
+
+		return []string{
+			symbol("render", prefix),
+		}
+	},
+	qrconst.SmileyFace: singleSymbol,
+	qrconst.Pointillism: func(lookahead qrconst.Lookahead) []string {
+		if lookahead.Has(qrconst.LookStructural) {
+			return []string{
+				symbol("render", "--structural"),
+			}
+		}
+
+		return []string{
+			symbol("render", "--"+strconv.Itoa(countNeighbors(lookahead))),
+		}
+	},
+}
+
+var ShapeMergeFunctions = map[qrconst.ModuleShape]func(lookahead qrconst.Lookahead) []string{
+	qrconst.TiedCircle: func(lookahead qrconst.Lookahead) []string {
+		var symbols []string
+
+		UR := lookahead.Has(qrconst.LookR, qrconst.LookUR, qrconst.LookU)
+		UL := lookahead.Has(qrconst.LookU, qrconst.LookUL, qrconst.LookL)
+		DL := lookahead.Has(qrconst.LookL, qrconst.LookDL, qrconst.LookD)
+		DR := lookahead.Has(qrconst.LookD, qrconst.LookDR, qrconst.LookR)
+
+		symbols = applyRules(
+			symbols, "merge",
+			[]rule{
+				{UR, "--ur"},
+				{UL, "--ul"},
+				{DL, "--dl"},
+				{DR, "--dr"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.Xs: func(lookahead qrconst.Lookahead) []string {
+		if lookahead.HasAny(qrconst.LookFinder, qrconst.LookSeparator) {
+			return nil
+		}
+
+		var symbols []string
+
+		UR := lookahead.Has(qrconst.LookR, qrconst.LookU)
+		UL := lookahead.Has(qrconst.LookU, qrconst.LookL)
+		DL := lookahead.Has(qrconst.LookL, qrconst.LookD)
+		DR := lookahead.Has(qrconst.LookD, qrconst.LookR)
+
+		symbols = applyRules(
+			symbols, "merge",
+			[]rule{
+				{UR, "--ur"},
+				{UL, "--ul"},
+				{DL, "--dl"},
+				{DR, "--dr"},
+			},
+		)
+
+		return symbols
+	},
+	qrconst.Pointillism: func(lookahead qrconst.Lookahead) []string {
+		if lookahead.HasAny(qrconst.LookFinder, qrconst.LookSeparator) {
+			return nil
+		}
+
+		return []string{
+			symbol("merge", "--"+strconv.Itoa(countNeighbors(lookahead))),
+		}
+	},
+}
+
+func singleSymbol(lookahead qrconst.Lookahead) []string {
+	return []string{
+		symbol("render", ""),
+	}
+}
+
+func mandorlaSymbols(lookahead qrconst.Lookahead) []string {
+	R := lookahead.Has(qrconst.LookR)
+	D := lookahead.Has(qrconst.LookD)
+
+	rules := []rule{
+		{R && D, "--r-d"},
+		{R, "--r"},
+		{D, "--d"},
+	}
+
+	for _, rule := range rules {
+		if rule.cond {
+			return []string{
+				symbol("render", rule.suffix),
+			}
+		}
+	}
+
+	return []string{
+		symbol("render", ""),
+	}
+}
+
+func structuralSymbols(lookahead qrconst.Lookahead) []string {
+	var suffix string
+	if lookahead.Has(qrconst.LookStructural) {
+		suffix += "--structural"
+	}
+
+	return []string{
+		symbol("render", suffix),
+	}
+}
+
+// countNeighbors returns the number of dark neighbors of the module.
+func countNeighbors(lookahead qrconst.Lookahead) int {
+	neighbors := 0
+	mask := qrconst.LookR
+	for range 8 {
+		if lookahead&mask != 0 {
+			neighbors++
+		}
+		mask <<= 1
+	}
+
+	return neighbors
+}
+
+func symbol(variant, suffix string) string {
+	return variant + suffix
+}
+
+func applyRules(
+	symbols []string,
+	variant string,
+	rules []rule,
+) []string {
+	for _, rule := range rules {
+		if rule.cond {
+			symbols = append(symbols, symbol(variant, rule.suffix))
+		}
+	}
+
+	return symbols
+}
@@ -0,0 +1,91 @@
+package vector
+
+import "math"
+
+// arcToCubics converts an SVG elliptical arc from p0 to p1 into cubic
+// Bézier curves of at most a quarter turn each, following the endpoint
+// to center conversion of the SVG specification (appendix B.2.4).
+func arcToCubics(p0, p1 Point, rx, ry, phiDeg float64, largeArc, sweep bool) []Segment {
+	if p0 == p1 {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []Segment{{Op: OpLineTo, Points: [3]Point{p1}}}
+	}
+
+	phi := phiDeg * math.Pi / 180
+	sinPhi, cosPhi := math.Sincos(phi)
+
+	// Midpoint in the rotated frame of the ellipse
+	dx, dy := (p0.X-p1.X)/2, (p0.Y-p1.Y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// Scale up radii that are too small to reach the end point
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx, ry = rx*s, ry*s
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+
+	cx := cosPhi*cx1 - sinPhi*cy1 + (p0.X+p1.X)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (p0.Y+p1.Y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// Point on the ellipse and its derivative at angle t
+	at := func(t float64) (p, d Point) {
+		sin, cos := math.Sincos(t)
+		p = Point{
+			cx + rx*cos*cosPhi - ry*sin*sinPhi,
+			cy + rx*cos*sinPhi + ry*sin*cosPhi,
+		}
+		d = Point{
+			-rx*sin*cosPhi - ry*cos*sinPhi,
+			-rx*sin*sinPhi + ry*cos*cosPhi,
+		}
+		return p, d
+	}
+
+	n := max(1, int(math.Ceil(math.Abs(delta)/(math.Pi/2)-1e-9)))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+
+	segments := make([]Segment, 0, n)
+	start, startD := at(theta)
+	for i := 1; i <= n; i++ {
+		end, endD := at(theta + float64(i)*step)
+		if i == n {
+			end = p1
+		}
+		segments = append(segments, Segment{
+			Op: OpCubeTo,
+			Points: [3]Point{
+				start.add(startD.scale(k)),
+				end.sub(endD.scale(k)),
+				end,
+			},
+		})
+		start, startD = end, endD
+	}
+
+	return segments
+}
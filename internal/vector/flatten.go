@@ -0,0 +1,98 @@
+package vector
+
+import "math"
+
+// Polyline is a flattened subpath.
+type Polyline struct {
+	Points []Point
+	Closed bool
+}
+
+// Flatten approximates the curves of the path with line segments,
+// deviating at most tolerance from them, and returns its subpaths.
+func (p Path) Flatten(tolerance float64) []Polyline {
+	var (
+		lines   []Polyline
+		current Polyline
+		start   Point
+		last    Point
+	)
+
+	flush := func() {
+		if len(current.Points) > 0 {
+			lines = append(lines, current)
+		}
+		current = Polyline{}
+	}
+	// Commands following a closepath start a new
+	// subpath at the start of the closed one
+	ensureStarted := func() {
+		if len(current.Points) == 0 {
+			current.Points = []Point{last}
+		}
+	}
+
+	for _, seg := range p {
+		switch seg.Op {
+		case OpMoveTo:
+			flush()
+			last = seg.Points[0]
+			start = last
+			current.Points = []Point{last}
+		case OpLineTo:
+			ensureStarted()
+			last = seg.Points[0]
+			current.Points = append(current.Points, last)
+		case OpQuadTo:
+			ensureStarted()
+			p0, p1, p2 := last, seg.Points[0], seg.Points[1]
+			dd := p0.sub(p1.scale(2)).add(p2).length()
+			n := curveSteps(dd/4, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				mt := 1 - t
+				current.Points = append(current.Points, Point{
+					mt*mt*p0.X + 2*mt*t*p1.X + t*t*p2.X,
+					mt*mt*p0.Y + 2*mt*t*p1.Y + t*t*p2.Y,
+				})
+			}
+			last = p2
+		case OpCubeTo:
+			ensureStarted()
+			p0, p1, p2, p3 := last, seg.Points[0], seg.Points[1], seg.Points[2]
+			dd := max(
+				p0.sub(p1.scale(2)).add(p2).length(),
+				p1.sub(p2.scale(2)).add(p3).length(),
+			)
+			n := curveSteps(.75*dd, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				mt := 1 - t
+				a, b, c, d := mt*mt*mt, 3*mt*mt*t, 3*mt*t*t, t*t*t
+				current.Points = append(current.Points, Point{
+					a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+					a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+				})
+			}
+			last = p3
+		case OpClose:
+			ensureStarted()
+			current.Closed = true
+			flush()
+			last = start
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// curveSteps returns the number of line segments approximating a
+// curve within tolerance, given the bound of its second derivative
+// divided by 8.
+func curveSteps(bound, tolerance float64) int {
+	if tolerance <= 0 {
+		tolerance = 1e-3
+	}
+	return max(1, int(math.Ceil(math.Sqrt(bound/tolerance))))
+}
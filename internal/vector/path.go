@@ -0,0 +1,336 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p Point) sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+func (p Point) scale(s float64) Point {
+	return Point{p.X * s, p.Y * s}
+}
+
+func (p Point) length() float64 {
+	return math.Hypot(p.X, p.Y)
+}
+
+type Op int
+
+const (
+	OpMoveTo Op = iota
+	OpLineTo
+	OpQuadTo
+	OpCubeTo
+	OpClose
+)
+
+// Segment is a path command in absolute coordinates. MoveTo and LineTo
+// use the first point, QuadTo the first two and CubeTo all three, the
+// last point used being the end point.
+type Segment struct {
+	Op     Op
+	Points [3]Point
+}
+
+// Path is a parsed SVG path, made of
+// lines and Bézier curves only.
+type Path []Segment
+
+// ParsePath parses SVG path data, e.g. "M 0 0 H 1 V 1 Z". Every command
+// is supported, in absolute and relative form. Elliptical arcs are
+// converted to cubic Bézier curves.
+func ParsePath(d string) (Path, error) {
+	s := pathScanner{s: d}
+
+	var (
+		path    Path
+		cmd     byte
+		current Point
+		start   Point
+		// Reflected control point of the previous curve, for S and T
+		control Point
+		prevCmd byte
+	)
+
+	for {
+		s.skipSeparators()
+		if s.done() {
+			break
+		}
+
+		if c := s.peek(); isCommand(c) {
+			cmd = c
+			s.i++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path data must start with a command, found %q", c)
+		} else if cmd == 'Z' || cmd == 'z' {
+			return nil, fmt.Errorf("unexpected number after closepath at offset %d", s.i)
+		}
+
+		relative := cmd >= 'a'
+		rel := func(p Point) Point {
+			if relative {
+				return p.add(current)
+			}
+			return p
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			current = rel(p)
+			start = current
+			path = append(path, Segment{Op: OpMoveTo, Points: [3]Point{current}})
+			// Subsequent pairs are implicit line commands
+			if relative {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'l':
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			current = rel(p)
+			path = append(path, Segment{Op: OpLineTo, Points: [3]Point{current}})
+		case 'H', 'h':
+			x, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				x += current.X
+			}
+			current.X = x
+			path = append(path, Segment{Op: OpLineTo, Points: [3]Point{current}})
+		case 'V', 'v':
+			y, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				y += current.Y
+			}
+			current.Y = y
+			path = append(path, Segment{Op: OpLineTo, Points: [3]Point{current}})
+		case 'Q', 'q', 'T', 't':
+			c := current
+			if cmd == 'Q' || cmd == 'q' {
+				p, err := s.point()
+				if err != nil {
+					return nil, err
+				}
+				c = rel(p)
+			} else if strings.IndexByte("QqTt", prevCmd) >= 0 {
+				c = current.scale(2).sub(control)
+			}
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			current = rel(p)
+			control = c
+			path = append(path, Segment{Op: OpQuadTo, Points: [3]Point{c, current}})
+		case 'C', 'c', 'S', 's':
+			c1 := current
+			if cmd == 'C' || cmd == 'c' {
+				p, err := s.point()
+				if err != nil {
+					return nil, err
+				}
+				c1 = rel(p)
+			} else if strings.IndexByte("CcSs", prevCmd) >= 0 {
+				c1 = current.scale(2).sub(control)
+			}
+			p2, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			p3, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			c2 := rel(p2)
+			current = rel(p3)
+			control = c2
+			path = append(path, Segment{Op: OpCubeTo, Points: [3]Point{c1, c2, current}})
+		case 'A', 'a':
+			var values [5]float64
+			for i := range values {
+				var err error
+				if i == 3 || i == 4 {
+					values[i], err = s.flag()
+				} else {
+					values[i], err = s.number()
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			end := rel(p)
+			path = append(path, arcToCubics(current, end, values[0], values[1], values[2], values[3] != 0, values[4] != 0)...)
+			current = end
+		case 'Z', 'z':
+			path = append(path, Segment{Op: OpClose})
+			current = start
+		default:
+			return nil, fmt.Errorf("unknown path command %q", cmd)
+		}
+
+		prevCmd = cmd
+	}
+
+	return path, nil
+}
+
+// MustParsePath is like ParsePath, but panics if the path data is invalid.
+func MustParsePath(d string) Path {
+	path, err := ParsePath(d)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// Bounds returns the bounding box of the path, including
+// the control points of its curves.
+func (p Path) Bounds() (minP, maxP Point) {
+	minP = Point{math.Inf(1), math.Inf(1)}
+	maxP = Point{math.Inf(-1), math.Inf(-1)}
+	for _, seg := range p {
+		for _, pt := range seg.points() {
+			minP = Point{min(minP.X, pt.X), min(minP.Y, pt.Y)}
+			maxP = Point{max(maxP.X, pt.X), max(maxP.Y, pt.Y)}
+		}
+	}
+
+	return minP, maxP
+}
+
+// points returns the points used by the segment.
+func (s Segment) points() []Point {
+	switch s.Op {
+	case OpMoveTo, OpLineTo:
+		return s.Points[:1]
+	case OpQuadTo:
+		return s.Points[:2]
+	case OpCubeTo:
+		return s.Points[:3]
+	}
+	return nil
+}
+
+func isCommand(c byte) bool {
+	return strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0
+}
+
+type pathScanner struct {
+	s string
+	i int
+}
+
+func (s *pathScanner) done() bool {
+	return s.i >= len(s.s)
+}
+
+func (s *pathScanner) peek() byte {
+	return s.s[s.i]
+}
+
+func (s *pathScanner) skipSeparators() {
+	for !s.done() {
+		switch s.peek() {
+		case ' ', '\t', '\n', '\r', '\f', ',':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+// number scans a number, which may directly follow the previous one
+// without a separator, e.g. ".5.5" or "1-1".
+func (s *pathScanner) number() (float64, error) {
+	s.skipSeparators()
+	start := s.i
+	if !s.done() && (s.peek() == '+' || s.peek() == '-') {
+		s.i++
+	}
+
+	digits, dot := false, false
+	for !s.done() {
+		c := s.peek()
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		s.i++
+	}
+
+	if digits && !s.done() && (s.peek() == 'e' || s.peek() == 'E') {
+		exp := s.i
+		s.i++
+		if !s.done() && (s.peek() == '+' || s.peek() == '-') {
+			s.i++
+		}
+		expDigits := false
+		for !s.done() && s.peek() >= '0' && s.peek() <= '9' {
+			expDigits = true
+			s.i++
+		}
+		if !expDigits {
+			s.i = exp
+		}
+	}
+
+	if !digits {
+		return 0, fmt.Errorf("expected a number at offset %d of path data %q", start, s.s)
+	}
+	return strconv.ParseFloat(s.s[start:s.i], 64)
+}
+
+func (s *pathScanner) point() (Point, error) {
+	x, err := s.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := s.number()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{x, y}, nil
+}
+
+// flag scans an arc flag, which is a single digit.
+func (s *pathScanner) flag() (float64, error) {
+	s.skipSeparators()
+	if s.done() || (s.peek() != '0' && s.peek() != '1') {
+		return 0, fmt.Errorf("expected an arc flag at offset %d of path data %q", s.i, s.s)
+	}
+	s.i++
+	return float64(s.s[s.i-1] - '0'), nil
+}
@@ -0,0 +1,160 @@
+package vector
+
+import (
+	"image"
+	"math"
+	"slices"
+)
+
+// subScanlines is the number of scanlines sampled per row of pixels.
+// Coverage is exact along each scanline.
+const subScanlines = 16
+
+// Transform scales the path, then offsets it.
+func (p Path) Transform(scale float64, offset Point) Path {
+	transformed := make(Path, len(p))
+	for i, seg := range p {
+		transformed[i].Op = seg.Op
+		for j, pt := range seg.points() {
+			transformed[i].Points[j] = pt.scale(scale).add(offset)
+		}
+	}
+
+	return transformed
+}
+
+// Fill returns the polygons filling the polylines. Open
+// polylines are implicitly closed, like SVG fills.
+func Fill(lines []Polyline) [][]Point {
+	polygons := make([][]Point, 0, len(lines))
+	for _, line := range lines {
+		if len(line.Points) > 2 {
+			polygons = append(polygons, line.Points)
+		}
+	}
+
+	return polygons
+}
+
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// Coverage returns the fraction of every pixel of bounds covered by the
+// polygons, given in pixel coordinates, filled with the nonzero rule.
+// Every row of pixels is sampled by subScanlines scanlines, along which
+// the coverage is computed exactly, so that overlapping polygons are
+// counted once.
+func Coverage(polygons [][]Point, bounds image.Rectangle) *image.Alpha {
+	mask := image.NewAlpha(bounds)
+	if bounds.Empty() {
+		return mask
+	}
+
+	var edges []edge
+	for _, polygon := range polygons {
+		for i, a := range polygon {
+			b := polygon[(i+1)%len(polygon)]
+			switch {
+			case a.Y < b.Y:
+				edges = append(edges, edge{a.X, a.Y, b.X, b.Y, 1})
+			case a.Y > b.Y:
+				edges = append(edges, edge{b.X, b.Y, a.X, a.Y, -1})
+			}
+		}
+	}
+	slices.SortFunc(edges, func(a, b edge) int {
+		return cmpFloat(a.y0, b.y0)
+	})
+
+	width := bounds.Dx()
+	row := make([]float64, width+1)
+	var (
+		active    []edge
+		crossings []crossing
+	)
+	next := 0
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		clear(row)
+
+		for s := range subScanlines {
+			y := float64(py) + (float64(s)+.5)/subScanlines
+
+			// Update the edges crossing the scanline
+			for next < len(edges) && edges[next].y0 <= y {
+				active = append(active, edges[next])
+				next++
+			}
+			active = slices.DeleteFunc(active, func(e edge) bool {
+				return e.y1 <= y
+			})
+
+			crossings = crossings[:0]
+			for _, e := range active {
+				if e.y0 > y {
+					continue
+				}
+				x := e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+				crossings = append(crossings, crossing{x, e.dir})
+			}
+			slices.SortFunc(crossings, func(a, b crossing) int {
+				return cmpFloat(a.x, b.x)
+			})
+
+			winding, start := 0, 0.0
+			for _, c := range crossings {
+				if winding == 0 {
+					start = c.x
+				}
+				winding += c.dir
+				if winding == 0 {
+					addSpan(row, start-float64(bounds.Min.X), c.x-float64(bounds.Min.X))
+				}
+			}
+		}
+
+		pix := mask.Pix[mask.PixOffset(bounds.Min.X, py):]
+		for x := range width {
+			pix[x] = uint8(math.Round(min(1, row[x]/subScanlines) * 255))
+		}
+	}
+
+	return mask
+}
+
+// addSpan adds the coverage of the span from x0 to x1 to the row.
+func addSpan(row []float64, x0, x1 float64) {
+	width := float64(len(row) - 1)
+	x0, x1 = max(0, x0), min(width, x1)
+	if x0 >= x1 {
+		return
+	}
+
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		row[i0] += x1 - x0
+		return
+	}
+	row[i0] += float64(i0+1) - x0
+	for i := i0 + 1; i < i1; i++ {
+		row[i]++
+	}
+	row[i1] += x1 - float64(i1)
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,204 @@
+package vector
+
+import (
+	"math"
+
+	"github.com/ahmadnaufalhakim/qrgen/internal/qrconst"
+)
+
+// miterLimit is the SVG default stroke-miterlimit.
+const miterLimit = 4
+
+// Stroke returns the outline of the stroked polylines as polygons
+// whose union is the stroke: one quadrilateral per segment, plus the
+// joins and the caps. Curves are approximated within tolerance.
+func Stroke(
+	lines []Polyline,
+	width float64,
+	lineCap qrconst.LineCap,
+	lineJoin qrconst.LineJoin,
+	tolerance float64,
+) [][]Point {
+	hw := width / 2
+	if hw <= 0 {
+		return nil
+	}
+
+	var polygons [][]Point
+	add := func(polygon ...Point) {
+		polygons = append(polygons, polygon)
+	}
+
+	for _, line := range lines {
+		pts := dedupe(line.Points, line.Closed)
+
+		// Zero length subpaths only show their caps
+		if len(pts) == 1 {
+			switch lineCap {
+			case qrconst.RoundCap:
+				add(circle(pts[0], hw, tolerance)...)
+			case qrconst.SquareCap:
+				p := pts[0]
+				add(
+					Point{p.X - hw, p.Y - hw}, Point{p.X + hw, p.Y - hw},
+					Point{p.X + hw, p.Y + hw}, Point{p.X - hw, p.Y + hw},
+				)
+			}
+			continue
+		}
+
+		n := len(pts) - 1
+		if line.Closed {
+			n = len(pts)
+		}
+		for i := range n {
+			a, b := pts[i], pts[(i+1)%len(pts)]
+			d := unit(b.sub(a))
+			o := normal(d).scale(hw)
+			add(a.add(o), b.add(o), b.sub(o), a.sub(o))
+		}
+
+		// Joins between consecutive segments
+		for i := range pts {
+			if !line.Closed && (i == 0 || i == len(pts)-1) {
+				continue
+			}
+			prev := pts[(i-1+len(pts))%len(pts)]
+			v := pts[i]
+			next := pts[(i+1)%len(pts)]
+			if join := joinPolygon(prev, v, next, hw, lineJoin, tolerance); join != nil {
+				add(join...)
+			}
+		}
+
+		if line.Closed {
+			continue
+		}
+
+		// Caps at both ends of open subpaths
+		ends := [2][2]Point{
+			{pts[0], unit(pts[0].sub(pts[1]))},
+			{pts[len(pts)-1], unit(pts[len(pts)-1].sub(pts[len(pts)-2]))},
+		}
+		for _, end := range ends {
+			p, d := end[0], end[1]
+			switch lineCap {
+			case qrconst.RoundCap:
+				add(circle(p, hw, tolerance)...)
+			case qrconst.SquareCap:
+				o := normal(d).scale(hw)
+				e := d.scale(hw)
+				add(p.add(o), p.add(o).add(e), p.sub(o).add(e), p.sub(o))
+			}
+		}
+	}
+
+	for _, polygon := range polygons {
+		orient(polygon)
+	}
+
+	return polygons
+}
+
+// joinPolygon returns the polygon filling the outer
+// corner of the segments prev-v and v-next.
+func joinPolygon(prev, v, next Point, hw float64, lineJoin qrconst.LineJoin, tolerance float64) []Point {
+	d0, d1 := unit(v.sub(prev)), unit(next.sub(v))
+	cross := d0.X*d1.Y - d0.Y*d1.X
+	dot := d0.X*d1.X + d0.Y*d1.Y
+
+	if lineJoin == qrconst.RoundJoin {
+		if math.Abs(cross) < 1e-12 && dot > 0 {
+			return nil
+		}
+		return circle(v, hw, tolerance)
+	}
+	if math.Abs(cross) < 1e-12 {
+		return nil
+	}
+
+	// The outer corner is on the side opposite to the turn
+	side := -1.0
+	if cross < 0 {
+		side = 1
+	}
+	n0, n1 := normal(d0).scale(side), normal(d1).scale(side)
+	p0, p1 := v.add(n0.scale(hw)), v.add(n1.scale(hw))
+
+	if lineJoin == qrconst.MiterJoin {
+		m := n0.add(n1)
+		// The ratio of the miter length to the stroke width
+		// is 1/cos(θ/2), where |m| = 2cos(θ/2)
+		if l := m.length(); l > 0 && 2/l <= miterLimit {
+			tip := v.add(m.scale(2 * hw / (l * l)))
+			return []Point{v, p0, tip, p1}
+		}
+	}
+
+	return []Point{v, p0, p1}
+}
+
+// dedupe removes consecutive duplicate points, and the
+// last point of a closed polyline if it repeats the first.
+func dedupe(points []Point, closed bool) []Point {
+	pts := make([]Point, 0, len(points))
+	for _, p := range points {
+		if len(pts) == 0 || p.sub(pts[len(pts)-1]).length() > 1e-12 {
+			pts = append(pts, p)
+		}
+	}
+	if closed && len(pts) > 1 && pts[0].sub(pts[len(pts)-1]).length() <= 1e-12 {
+		pts = pts[:len(pts)-1]
+	}
+
+	return pts
+}
+
+// circle returns a polygon inscribed in the circle,
+// deviating at most tolerance from it.
+func circle(c Point, r, tolerance float64) []Point {
+	n := 8
+	if tolerance > 0 && tolerance < r {
+		n = max(n, int(math.Ceil(math.Pi/math.Acos(1-tolerance/r))))
+	}
+
+	pts := make([]Point, n)
+	for i := range pts {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = Point{c.X + r*cos, c.Y + r*sin}
+	}
+
+	return pts
+}
+
+func unit(p Point) Point {
+	l := p.length()
+	if l == 0 {
+		return Point{}
+	}
+	return p.scale(1 / l)
+}
+
+func normal(d Point) Point {
+	return Point{-d.Y, d.X}
+}
+
+// orient reverses the polygon if needed so that its signed area is
+// positive. Overlapping polygons of the same orientation then add up
+// instead of cancelling out under the nonzero rule.
+func orient(polygon []Point) {
+	if signedArea(polygon) < 0 {
+		for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+			polygon[i], polygon[j] = polygon[j], polygon[i]
+		}
+	}
+}
+
+func signedArea(polygon []Point) float64 {
+	area := 0.0
+	for i, p := range polygon {
+		q := polygon[(i+1)%len(polygon)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area / 2
+}